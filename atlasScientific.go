@@ -2,32 +2,134 @@ package atlasScientific
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
 	"github.com/idahoakl/go-i2c"
+	"math"
 	"regexp"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 )
 
 var (
 	statusRegex     = regexp.MustCompile(`\?STATUS,(?P<restartCode>\D),(?P<vccVolt>\d+\.?\d*)`)
 	deviceInfoRegex = regexp.MustCompile(`\?I,(?P<deviceType>\w+),(?P<firmwareVersion>\d+\.?\d*)`)
-	tempCompRegex   = regexp.MustCompile(`\?T,(?P<tempCompensation>\d+\.?\d*)`)
+	tempCompRegex   = regexp.MustCompile(`\?T,(?P<tempCompensation>-?\d+\.?\d*)`)
 	ledStatRegex    = regexp.MustCompile(`\?L,(?P<ledStatus>[01])`)
 	calRegex        = regexp.MustCompile(`\?CAL,(?P<calCount>\d)`)
+	nameRegex       = regexp.MustCompile(`(?i)\?NAME,(?P<name>.*)`)
+	plockRegex      = regexp.MustCompile(`(?i)\?PLOCK,(?P<protocolLock>[01])`)
 
 	errParseResponse = errors.New("Response could not be parsed")
+
+	//ErrNotConnected is returned by commands issued before Connect has succeeded
+	ErrNotConnected = errors.New("Not connected")
+
+	//ErrCompensationUnavailable is returned by a CompensationProvider for values it cannot measure
+	ErrCompensationUnavailable = errors.New("Compensation value unavailable")
+
+	//DefaultTempCompensationRange covers the operating temperature of the pH and
+	//conductivity probes.  Values outside it almost always come from a faulty
+	//temperature source.
+	DefaultTempCompensationRange = Bounds{Min: -5, Max: 100}
+
+	//DefaultRetryPolicy re-reads once using the command's original wait time
+	DefaultRetryPolicy = RetryPolicy{Retries: 1}
 )
 
 const ERROR_VALUE = -1
 
+const selfTestMaxReadings = 30
+
+//ResponseCode is the status byte that prefixes every response read from a device
+type ResponseCode byte
+
+const (
+	ResponseSuccess     ResponseCode = 1
+	ResponseSyntaxError ResponseCode = 2
+	ResponsePending     ResponseCode = 254
+	ResponseNoData      ResponseCode = 255
+)
+
 type AtlasScientific struct {
 	Connection *i2c.I2C
 	Address    uint8
+	Bus        int
 	Mtx        sync.Mutex
+	DataReady  DataReadyNotifier
+	//Transport is used instead of Connection when set.  Reconnect only applies
+	//to Connection.
+	Transport Transport
+	//Logger is the parent entry used by GetContextLogger.  Defaults to the
+	//standard logrus logger when nil.
+	Logger *log.Entry
+	//LogFields are added to every log line emitted for this device
+	LogFields log.Fields
+	//Audit receives an entry for every state-changing command when set
+	Audit AuditSink
+	//ReadRetryPolicy applies to reads, queries and configuration commands.
+	//DefaultRetryPolicy is used when nil.
+	ReadRetryPolicy *RetryPolicy
+	//CalibrationRetryPolicy applies to calibration commands.  DefaultRetryPolicy
+	//is used when nil.
+	CalibrationRetryPolicy *RetryPolicy
+	//Observer is notified after every command for tracing and metrics
+	Observer CommandObserver
+	//Bounds overrides the sensor's default plausibility range for readings
+	Bounds *Bounds
+	//Reconnect enables reopening the connection's bus after a transport error
+	//when set.  A connection passed in by the caller may be shared with other
+	//devices, so it is left open and a new one is opened in its place.
+	Reconnect *ReconnectPolicy
+	//DryRun logs state-changing commands such as calibration, temperature
+	//compensation and output parameters instead of sending them
+	DryRun bool
+	//TempCompensationDelta is the minimum change in temperature, in C, before
+	//Compensate or the T and R fallback of GetRawValueCompensated sends a new T
+	//command.  Zero sends on every call.
+	TempCompensationDelta float32
+	//History keeps recent readings when set.  See NewHistory.
+	History *History
+	//CoalesceWindow lets GetRawValue return the previous value instead of
+	//issuing another R command when that value is younger than the window, so
+	//callers polling the same device share one transaction.  Zero disables it.
+	CoalesceWindow time.Duration
+	//TempCompensationRange overrides DefaultTempCompensationRange
+	TempCompensationRange *Bounds
+	//RejectZeroTempCompensation rejects exactly 0C, which usually means an
+	//upstream temperature source has not been initialized
+	RejectZeroTempCompensation bool
+
+	lastReading     atomic.Value
+	lastCommand     string
+	firmwareVersion float32
+	tempComp        *float32
+	rtUnsupported   bool
+	valueRetries    int
+	ownsConnection  bool
+	lastValue       string
+	lastValueTime   time.Time
+}
+
+//Transport carries commands to and responses from a device.  *i2c.I2C
+//implements it; other implementations can bridge different buses or stand in
+//for hardware in tests.
+type Transport interface {
+	Read(address uint8, data []byte) (int, error)
+	Write(address uint8, data []byte) (int, error)
+}
+
+//DataReadyNotifier is implemented by carrier boards that expose a data-ready
+//interrupt line.  WaitDataReady should block until the device signals data is
+//available or the timeout elapses.
+type DataReadyNotifier interface {
+	WaitDataReady(timeout time.Duration) error
 }
 
 type Status struct {
@@ -35,6 +137,215 @@ type Status struct {
 	VccVoltage  float32
 }
 
+type Reading struct {
+	Value float32
+	Time  time.Time
+	//Valid is false when Value falls outside the sensor's plausibility bounds
+	Valid   bool
+	Quality Quality
+	//Compensation holds the compensation values known to be in effect when
+	//the reading was taken
+	Compensation CompensationValues
+}
+
+//Stale reports whether the reading is older than ttl
+func (this Reading) Stale(ttl time.Duration) bool {
+	return time.Since(this.Time) > ttl
+}
+
+//Quality grades how trustworthy a reading is
+type Quality int
+
+const (
+	QualityGood Quality = iota
+	//QualitySuspect readings needed re-reads or had parse anomalies
+	QualitySuspect
+	//QualityBad readings fall outside the sensor's plausibility bounds
+	QualityBad
+)
+
+func (this Quality) String() string {
+	switch this {
+	case QualityGood:
+		return "good"
+	case QualitySuspect:
+		return "suspect"
+	case QualityBad:
+		return "bad"
+	}
+
+	return fmt.Sprintf("Quality(%d)", int(this))
+}
+
+//CompensationValues are compensation settings last set on or read from the
+//device.  Nil fields are unknown or not supported by the device.
+type CompensationValues struct {
+	Temperature *float32
+	Salinity    *float32
+	Pressure    *float32
+}
+
+//AuditEntry describes a state-changing command sent to a device
+type AuditEntry struct {
+	Time    time.Time
+	Address uint8
+	Command string
+	Err     error
+}
+
+//AuditSink records state-changing commands such as calibration and temperature
+//compensation for traceability
+type AuditSink interface {
+	Record(entry AuditEntry)
+}
+
+//RetryPolicy controls how many times a response is re-read while the device
+//reports it is still processing the previous command
+type RetryPolicy struct {
+	//Retries is the number of re-reads after the initial read
+	Retries int
+	//RetryDelay is the wait before each re-read.  The command's initial wait
+	//time is reused when zero.
+	RetryDelay time.Duration
+	//MaxWait bounds the total time spent waiting, including the initial wait.
+	//Zero means no limit.
+	MaxWait time.Duration
+}
+
+//SelfTestResult is the outcome of comparing a stabilized reading against a
+//reference solution
+type SelfTestResult struct {
+	Expected  float32
+	Tolerance float32
+	Value     float32
+	Deviation float32
+	Passed    bool
+}
+
+//CompensationProvider supplies environmental values used for compensation,
+//typically from a non-Atlas sensor on the same bus.  Temperature is in C,
+//pressure in kPa and salinity in PSU.
+type CompensationProvider interface {
+	Temperature() (float32, error)
+	Pressure() (float32, error)
+	Salinity() (float32, error)
+}
+
+//CommandObserver is notified after each command round trip with its total
+//duration, the number of re-reads needed and the resulting error.  It allows
+//tracing and metrics such as OpenTelemetry to be attached by the application.
+type CommandObserver interface {
+	ObserveCommand(command string, duration time.Duration, retries int, err error)
+}
+
+//Description is static metadata about what a sensor measures
+type Description struct {
+	Type string
+	Unit string
+	//ValidRange is the default plausibility range for readings
+	ValidRange Bounds
+}
+
+//Bounds is an inclusive range of plausible values
+type Bounds struct {
+	Min float32
+	Max float32
+}
+
+func (this Bounds) Contains(value float32) bool {
+	return value >= this.Min && value <= this.Max
+}
+
+//CalibrationSpec describes one calibration point supported by a sensor
+type CalibrationSpec struct {
+	Point string
+	//RequiresValue is false for points such as conductivity "dry" that take no value
+	RequiresValue bool
+	ValidRange    Bounds
+	//Delay is the documented processing time after the command is written
+	Delay time.Duration
+	//ClearsExisting marks points that wipe previously stored calibration
+	ClearsExisting bool
+}
+
+//Sensor is implemented by every concrete sensor type so generic code can
+//describe, read and calibrate a sensor without switching on its type
+type Sensor interface {
+	AtlasScientificSensor
+	Describe() Description
+	Read() (Reading, error)
+	Calibrate(calPoint string, value float32) error
+	CalibrationSpecs() []CalibrationSpec
+}
+
+//TempCompensationError is returned when a temperature compensation value is
+//rejected before being sent to the device
+type TempCompensationError struct {
+	Temperature float32
+	Range       Bounds
+	//ZeroRejected is set when the value was rejected by RejectZeroTempCompensation
+	ZeroRejected bool
+}
+
+func (this *TempCompensationError) Error() string {
+	if this.ZeroRejected {
+		return "Temperature compensation of 0C rejected, temperature source may be unset"
+	}
+
+	return fmt.Sprintf("Temperature compensation %f outside of range %f to %f", this.Temperature, this.Range.Min, this.Range.Max)
+}
+
+//DeviceMismatchError is returned when the device at an address does not identify
+//as the expected type
+type DeviceMismatchError struct {
+	Expected string
+	Found    string
+	Address  uint8
+}
+
+func (this *DeviceMismatchError) Error() string {
+	return fmt.Sprintf("Expected %s, found %s at 0x%X", this.Expected, this.Found, this.Address)
+}
+
+//ReconnectPolicy controls how the bus is reopened after a transport error such
+//as EIO or the device node disappearing
+type ReconnectPolicy struct {
+	Attempts       int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+//ReadingStats summarizes a series of readings
+type ReadingStats struct {
+	Count  int
+	Mean   float32
+	Min    float32
+	Max    float32
+	StdDev float32
+}
+
+//ReadResponse is the full response to a command, for callers building their
+//own command wrappers
+type ReadResponse struct {
+	Status  ResponseCode
+	Payload string
+	//Raw is the unmodified buffer from the final read
+	Raw []byte
+	//Attempts is the number of reads, including re-reads while pending
+	Attempts int
+	//TotalWait is the time spent waiting for the device across all reads
+	TotalWait time.Duration
+}
+
+//DebugReading captures exactly what the device sent for a reading, for
+//diagnosing intermittent read and parse failures
+type DebugReading struct {
+	Status  ResponseCode
+	Raw     []byte
+	Payload string
+	Value   float32
+}
+
 type DeviceInfo struct {
 	Type            string
 	FirmwareVersion float32
@@ -54,8 +365,14 @@ type AtlasScientificSensor interface {
 	GetCalibrationCount() (int, error)
 }
 
+//LastReader is implemented by sensors that cache their most recent reading
+type LastReader interface {
+	LastReading() (Reading, bool)
+}
+
 type ReadError struct {
-	status  int
+	code    ResponseCode
+	command string
 	message string
 }
 
@@ -63,6 +380,16 @@ func (this *ReadError) Error() string {
 	return this.message
 }
 
+//Code returns the response status byte that caused the error
+func (this *ReadError) Code() ResponseCode {
+	return this.code
+}
+
+//Command returns the command that was rejected for syntax errors
+func (this *ReadError) Command() string {
+	return this.command
+}
+
 func (this *AtlasScientific) Init() error {
 	return nil
 }
@@ -75,15 +402,123 @@ func (this *AtlasScientific) GetRawValue() (string, error) {
 	this.Mtx.Lock()
 	defer this.Mtx.Unlock()
 
-	if _, e := this.Write("R"); e != nil {
+	if value, ok := this.CoalescedValue(); ok {
+		return value, nil
+	}
+
+	return this.readValue("R", 1000*time.Millisecond)
+}
+
+//CoalescedValue returns the previous raw value while it is within
+//CoalesceWindow.  The caller must hold Mtx.
+func (this *AtlasScientific) CoalescedValue() (string, bool) {
+	if this.CoalesceWindow > 0 && time.Since(this.lastValueTime) < this.CoalesceWindow {
+		return this.lastValue, true
+	}
+
+	return "", false
+}
+
+//readValue issues a reading command, remembering how many re-reads it took so
+//RecordReading can grade the reading's quality
+func (this *AtlasScientific) readValue(command string, waitTime time.Duration) (string, error) {
+	resp, e := this.readValueResponse(command, waitTime)
+	if e != nil {
 		return "", e
 	}
 
-	if data, e := this.PerformRead(1000 * time.Millisecond); e != nil {
+	return resp.payload(), nil
+}
+
+func (this *AtlasScientific) readValueResponse(command string, waitTime time.Duration) (*rawResponse, error) {
+	resp, e := this.writeReadResponse(command, waitTime, this.readRetryPolicy())
+
+	if resp != nil {
+		this.valueRetries = resp.attempts - 1
+	}
+
+	if e == nil {
+		this.lastValue = resp.payload()
+		this.lastValueTime = time.Now()
+	} else {
+		this.lastValueTime = time.Time{}
+	}
+
+	return resp, e
+}
+
+//GetRawValueCompensated sets temperature compensation and takes a reading in a
+//single RT command.  Firmware that rejects RT falls back to separate T and R
+//commands, and RT is not attempted again.
+//Example instruction sequence:
+//	Write: RT,19.5
+//	Wait: 900ms
+//	Read: <value>
+func (this *AtlasScientific) GetRawValueCompensated(tempC float32) (string, error) {
+	this.Mtx.Lock()
+	defer this.Mtx.Unlock()
+
+	if e := this.validateTempCompensation(tempC); e != nil {
+		return "", e
+	}
+
+	if !this.rtUnsupported && !this.DryRun {
+		command := fmt.Sprintf("RT,%f", tempC)
+		data, e := this.readValue(command, 900*time.Millisecond)
+		this.audit(command, e)
+
+		if readErr, ok := e.(*ReadError); ok && readErr.Code() == ResponseSyntaxError {
+			this.GetContextLogger().Info("RT not supported by firmware, using T and R")
+			this.rtUnsupported = true
+		} else if e != nil {
+			return "", e
+		} else {
+			this.tempComp = &tempC
+			return data, nil
+		}
+	}
+
+	if this.tempCompensationDue(tempC) {
+		if e := this.PerformCommand(fmt.Sprintf("T,%f", tempC), 300*time.Millisecond); e != nil {
+			return "", e
+		}
+
+		if !this.DryRun {
+			this.tempComp = &tempC
+		}
+	}
+
+	return this.readValue("R", 1000*time.Millisecond)
+}
+
+//ReadRawCompensated takes a reading compensated to the provider's temperature.
+//It uses RT where the firmware supports it.  Otherwise it falls back to T and
+//R, skipping T like Compensate does when the temperature has moved less than
+//TempCompensationDelta.
+func (this *AtlasScientific) ReadRawCompensated(provider CompensationProvider) (string, error) {
+	if tempC, e := provider.Temperature(); e != nil {
 		return "", e
 	} else {
-		return data, nil
+		return this.GetRawValueCompensated(tempC)
+	}
+}
+
+//GetRawValueDebug issues R like GetRawValue but returns the raw response.  The
+//DebugReading is non-nil whenever data was read, even if an error is returned.
+func (this *AtlasScientific) GetRawValueDebug() (*DebugReading, error) {
+	this.Mtx.Lock()
+	defer this.Mtx.Unlock()
+
+	resp, e := this.readValueResponse("R", 1000*time.Millisecond)
+	if resp == nil {
+		return nil, e
 	}
+
+	return &DebugReading{
+		Status:  resp.status(),
+		Raw:     resp.data,
+		Payload: resp.payload(),
+	}, e
 }
 
 func (this *AtlasScientific) GetValue() (float32, error) {
@@ -127,6 +562,8 @@ func (this *AtlasScientific) GetDeviceInfo() (*DeviceInfo, error) {
 		if f, e := strconv.ParseFloat(valMap["firmwareVersion"], 32); e != nil {
 			return nil, e
 		} else {
+			this.firmwareVersion = float32(f)
+
 			return &DeviceInfo{
 				Type:            valMap["deviceType"],
 				FirmwareVersion: float32(f),
@@ -149,7 +586,10 @@ func (this *AtlasScientific) GetTempCompensation() (float32, error) {
 		if tempComp, err := strconv.ParseFloat(valMap["tempCompensation"], 32); err != nil {
 			return 0, err
 		} else {
-			return float32(tempComp), nil
+			tempC := float32(tempComp)
+			this.tempComp = &tempC
+
+			return tempC, nil
 		}
 	}
 }
@@ -162,17 +602,83 @@ func (this *AtlasScientific) TempCompensation(tempC float32) error {
 	this.Mtx.Lock()
 	defer this.Mtx.Unlock()
 
-	if _, e := this.Write(fmt.Sprintf("T,%f", tempC)); e != nil {
+	if e := this.validateTempCompensation(tempC); e != nil {
 		return e
 	}
 
-	if _, e := this.PerformRead(300 * time.Millisecond); e != nil {
+	if e := this.PerformCommand(fmt.Sprintf("T,%f", tempC), 300*time.Millisecond); e != nil {
+		return e
+	}
+
+	if !this.DryRun {
+		this.tempComp = &tempC
+	}
+
+	return nil
+}
+
+func (this *AtlasScientific) validateTempCompensation(tempC float32) error {
+	bounds := DefaultTempCompensationRange
+
+	if this.TempCompensationRange != nil {
+		bounds = *this.TempCompensationRange
+	}
+
+	if tempC == 0 && this.RejectZeroTempCompensation {
+		return &TempCompensationError{Temperature: tempC, Range: bounds, ZeroRejected: true}
+	} else if !bounds.Contains(tempC) {
+		return &TempCompensationError{Temperature: tempC, Range: bounds}
+	}
+
+	return nil
+}
+
+//VerifyDeviceType queries the device type and returns a DeviceMismatchError if it
+//is not expectedType
+func (this *AtlasScientific) VerifyDeviceType(expectedType string) error {
+	if info, e := this.GetDeviceInfo(); e != nil {
 		return e
+	} else if !strings.EqualFold(info.Type, expectedType) {
+		return &DeviceMismatchError{
+			Expected: expectedType,
+			Found:    info.Type,
+			Address:  this.Address,
+		}
 	}
 
 	return nil
 }
 
+//Compensate applies the provider's temperature as the device's temperature
+//compensation.  The update is skipped when the temperature has moved less than
+//TempCompensationDelta since it was last set.  Callers that read right after
+//compensating should use ReadRawCompensated or the sensor's ReadCompensated,
+//which combine both in a single RT command.
+func (this *AtlasScientific) Compensate(provider CompensationProvider) error {
+	if tempC, e := provider.Temperature(); e != nil {
+		return e
+	} else {
+		if !this.tempCompensationChanged(tempC) {
+			return nil
+		}
+
+		return this.TempCompensation(tempC)
+	}
+}
+
+func (this *AtlasScientific) tempCompensationChanged(tempC float32) bool {
+	this.Mtx.Lock()
+	defer this.Mtx.Unlock()
+
+	return this.tempCompensationDue(tempC)
+}
+
+//tempCompensationDue reports whether tempC differs enough from the current
+//compensation to send.  The caller must hold Mtx.
+func (this *AtlasScientific) tempCompensationDue(tempC float32) bool {
+	return this.tempComp == nil || abs32(tempC-*this.tempComp) >= this.TempCompensationDelta
+}
+
 //Example instruction sequence:
 //	Write: L,?
 //	Wait: 300ms
@@ -192,6 +698,40 @@ func (this *AtlasScientific) GetLedStatus() (bool, error) {
 	}
 }
 
+//Example instruction sequence:
+//	Write: Name,?
+//	Wait: 300ms
+//	Read: ?Name,tank1
+func (this *AtlasScientific) GetName() (string, error) {
+	this.Mtx.Lock()
+	defer this.Mtx.Unlock()
+
+	if valMap, e := this.WriteReadParse("Name,?", 300*time.Millisecond, nameRegex); e != nil {
+		return "", e
+	} else {
+		return valMap["name"], nil
+	}
+}
+
+//Example instruction sequence:
+//	Write: Plock,?
+//	Wait: 300ms
+//	Read: ?Plock,1
+func (this *AtlasScientific) GetProtocolLock() (bool, error) {
+	this.Mtx.Lock()
+	defer this.Mtx.Unlock()
+
+	if valMap, e := this.WriteReadParse("Plock,?", 300*time.Millisecond, plockRegex); e != nil {
+		return false, e
+	} else {
+		if locked, err := strconv.ParseBool(valMap["protocolLock"]); err != nil {
+			return false, err
+		} else {
+			return locked, nil
+		}
+	}
+}
+
 //Example instruction sequence:
 //	Write: L,1
 //	Wait: 300ms
@@ -206,15 +746,46 @@ func (this *AtlasScientific) LedStatus(isLedOn bool) error {
 		writeCmd = "L,1"
 	}
 
-	if _, e := this.Write(writeCmd); e != nil {
-		return e
+	return this.PerformCommand(writeCmd, 300*time.Millisecond)
+}
+
+//Calibrate validates value against spec and sends the calibration command
+//Example instruction sequence:
+//	Write: CAL,mid,7
+//	Wait: spec.Delay
+//	Read: <successful read, no data>
+func (this *AtlasScientific) Calibrate(spec CalibrationSpec, value float32) error {
+	this.Mtx.Lock()
+	defer this.Mtx.Unlock()
+
+	command := "CAL," + spec.Point
+
+	if spec.RequiresValue {
+		if !spec.ValidRange.Contains(value) {
+			return errors.New(fmt.Sprintf("Invalid value '%f' for calibration point '%s'.  Must be between %g and %g.",
+				value, spec.Point, spec.ValidRange.Min, spec.ValidRange.Max))
+		}
+
+		command += "," + strconv.FormatFloat(float64(value), 'f', -1, 32)
 	}
 
-	if _, e := this.PerformRead(300 * time.Millisecond); e != nil {
-		return e
+	return this.PerformCalibration(command, spec.Delay)
+}
+
+//FindCalibrationSpec returns the spec in specs for point
+func FindCalibrationSpec(specs []CalibrationSpec, point string) (CalibrationSpec, error) {
+	var names []string
+
+	for _, spec := range specs {
+		if spec.Point == point {
+			return spec, nil
+		}
+
+		names = append(names, spec.Point)
 	}
 
-	return nil
+	return CalibrationSpec{}, errors.New(fmt.Sprintf("Invalid calPoint value '%s'.  Valid values: %s",
+		point, strings.Join(names, ", ")))
 }
 
 //Example instruction sequence:
@@ -225,15 +796,7 @@ func (this *AtlasScientific) ClearCalibration() error {
 	this.Mtx.Lock()
 	defer this.Mtx.Unlock()
 
-	if _, e := this.Write("CAL,clear"); e != nil {
-		return e
-	}
-
-	if _, e := this.PerformRead(1300 * time.Millisecond); e != nil {
-		return e
-	}
-
-	return nil
+	return this.PerformCalibration("CAL,clear", 1300*time.Millisecond)
 }
 
 //Example instruction sequence:
@@ -255,50 +818,314 @@ func (this *AtlasScientific) GetCalibrationCount() (int, error) {
 	}
 }
 
+//LastReading returns the most recently recorded reading without communicating
+//with the device or waiting on Mtx.  ok is false if nothing has been recorded yet.
+func (this *AtlasScientific) LastReading() (reading Reading, ok bool) {
+	reading, ok = this.lastReading.Load().(Reading)
+	return
+}
+
+//RecordReading stores value as the most recent reading returned by LastReading.
+//The reading is marked invalid if it falls outside Bounds, or defaultBounds when
+//Bounds is not set.  quality is the caller's assessment of the parsed value and
+//is downgraded if the read needed retries or the value is out of bounds.  Must
+//not be called while holding Mtx.
+func (this *AtlasScientific) RecordReading(value float32, defaultBounds Bounds, quality Quality) Reading {
+	bounds := defaultBounds
+
+	if this.Bounds != nil {
+		bounds = *this.Bounds
+	}
+
+	compensation, retries := this.readingContext()
+
+	reading := Reading{
+		Value:        value,
+		Time:         time.Now(),
+		Valid:        bounds.Contains(value),
+		Quality:      quality,
+		Compensation: compensation,
+	}
+
+	if retries > 0 && reading.Quality < QualitySuspect {
+		reading.Quality = QualitySuspect
+	}
+
+	if !reading.Valid {
+		reading.Quality = QualityBad
+
+		this.GetContextLogger().WithFields(log.Fields{
+			"value":  value,
+			"bounds": bounds,
+		}).Warn("Reading outside of plausible range")
+	}
+
+	this.lastReading.Store(reading)
+
+	if this.History != nil {
+		this.History.Add(reading)
+	}
+
+	return reading
+}
+
+//CurrentCompensation returns the compensation values known to be in effect
+func (this *AtlasScientific) CurrentCompensation() CompensationValues {
+	values, _ := this.readingContext()
+	return values
+}
+
+//readingContext returns the compensation values in effect and the number of
+//re-reads needed by the last reading command
+func (this *AtlasScientific) readingContext() (CompensationValues, int) {
+	this.Mtx.Lock()
+	defer this.Mtx.Unlock()
+
+	var values CompensationValues
+
+	if this.tempComp != nil {
+		tempC := *this.tempComp
+		values.Temperature = &tempC
+	}
+
+	return values, this.valueRetries
+}
+
+//PerformCommand writes a state-changing command and waits for the device to
+//acknowledge it.  The outcome is recorded to the Audit sink when one is set.
+func (this *AtlasScientific) PerformCommand(command string, waitTime time.Duration) error {
+	return this.performCommand(command, waitTime, this.readRetryPolicy())
+}
+
+//PerformCalibration behaves like PerformCommand but re-reads according to
+//CalibrationRetryPolicy, since calibration can take longer than documented.
+func (this *AtlasScientific) PerformCalibration(command string, waitTime time.Duration) error {
+	return this.performCommand(command, waitTime, this.calibrationRetryPolicy())
+}
+
+func (this *AtlasScientific) performCommand(command string, waitTime time.Duration, policy RetryPolicy) error {
+	if this.DryRun {
+		this.GetContextLogger().WithFields(log.Fields{
+			"command":  command,
+			"waitTime": waitTime,
+		}).Info("Dry run, command not sent")
+
+		return nil
+	}
+
+	//State changes such as compensation or calibration make the previous
+	//value unsuitable for coalescing
+	this.lastValueTime = time.Time{}
+
+	e := this.writeAndAcknowledge(command, waitTime, policy)
+	this.audit(command, e)
+
+	return e
+}
+
+func (this *AtlasScientific) audit(command string, e error) {
+	if this.Audit != nil {
+		this.Audit.Record(AuditEntry{
+			Time:    time.Now(),
+			Address: this.Address,
+			Command: command,
+			Err:     e,
+		})
+	}
+}
+
+func (this *AtlasScientific) writeAndAcknowledge(command string, waitTime time.Duration, policy RetryPolicy) error {
+	_, e := this.writeRead(command, waitTime, policy)
+	return e
+}
+
+//WriteRead writes a command and returns the response read after waitTime
+func (this *AtlasScientific) WriteRead(command string, waitTime time.Duration) (string, error) {
+	return this.writeRead(command, waitTime, this.readRetryPolicy())
+}
+
+//WriteReadResponse behaves like WriteRead but returns the full response.  The
+//ReadResponse is non-nil whenever data was read, even if an error is returned.
+//The caller must hold Mtx.
+func (this *AtlasScientific) WriteReadResponse(command string, waitTime time.Duration, policy RetryPolicy) (*ReadResponse, error) {
+	resp, e := this.writeReadResponse(command, waitTime, policy)
+	return resp.export(), e
+}
+
+func (this *AtlasScientific) writeRead(command string, waitTime time.Duration, policy RetryPolicy) (string, error) {
+	if resp, e := this.writeReadResponse(command, waitTime, policy); e != nil {
+		return "", e
+	} else {
+		return resp.payload(), nil
+	}
+}
+
+//writeReadResponse returns the raw response, which is non-nil whenever data
+//was read from the device, even if the response indicated an error
+func (this *AtlasScientific) writeReadResponse(command string, waitTime time.Duration, policy RetryPolicy) (*rawResponse, error) {
+	start := time.Now()
+
+	resp, e := this.writeReadRetry(command, waitTime, policy)
+
+	if this.Observer != nil {
+		retries := 0
+		if resp != nil {
+			retries = resp.attempts - 1
+		}
+
+		this.Observer.ObserveCommand(command, time.Since(start), retries, e)
+	}
+
+	return resp, e
+}
+
+func (this *AtlasScientific) writeReadRetry(command string, waitTime time.Duration, policy RetryPolicy) (*rawResponse, error) {
+	if _, e := this.Write(command); e != nil {
+		return nil, e
+	}
+
+	return this.performReadRetry(waitTime, policy)
+}
+
 func (this *AtlasScientific) PerformRead(waitTime time.Duration) (string, error) {
-	time.Sleep(waitTime)
+	return this.PerformReadRetry(waitTime, this.readRetryPolicy())
+}
 
-	data := make([]byte, 64)
-	if _, e := this.Connection.Read(this.Address, data); e != nil {
+//PerformReadRetry waits for and reads a response, re-reading while the device
+//reports it is still processing as allowed by policy.
+func (this *AtlasScientific) PerformReadRetry(waitTime time.Duration, policy RetryPolicy) (string, error) {
+	if resp, e := this.performReadRetry(waitTime, policy); e != nil {
 		return "", e
+	} else {
+		return resp.payload(), nil
 	}
+}
 
-	//this.GetContextLogger().WithField("data", data).Debug("Raw data read from device")
+//PerformReadResponse behaves like PerformReadRetry but returns the full
+//response.  The ReadResponse is non-nil whenever data was read, even if an
+//error is returned.
+func (this *AtlasScientific) PerformReadResponse(waitTime time.Duration, policy RetryPolicy) (*ReadResponse, error) {
+	resp, e := this.performReadRetry(waitTime, policy)
+	return resp.export(), e
+}
 
-	e := checkReadError(data)
-	if e != nil {
-		if e.status == 254 {
-			this.GetContextLogger().WithField("waitTime", waitTime).Warn("Attempting re-read after additional wait time")
-			//If read wasn't ready try once more
-			time.Sleep(waitTime)
-			if _, e := this.Connection.Read(this.Address, data); e != nil {
-				return "", e
-			}
+//rawResponse is the last buffer read from the device along with the number of
+//reads it took
+type rawResponse struct {
+	data      []byte
+	attempts  int
+	totalWait time.Duration
+}
+
+func (this *rawResponse) status() ResponseCode {
+	return ResponseCode(this.data[0])
+}
 
-			//this.GetContextLogger().WithField("data", data).Debug("Raw data read from device")
+func (this *rawResponse) payload() string {
+	trimData := bytes.Trim(this.data, "\x00")
 
-			if e := checkReadError(data); e != nil {
-				return "", e
+	//this.GetContextLogger().WithField("trimmedData", trimData).Debug("Trimmed data")
+
+	if len(trimData) == 0 {
+		return ""
+	}
+
+	return string(trimData[1:])
+}
+
+func (this *rawResponse) export() *ReadResponse {
+	if this == nil {
+		return nil
+	}
+
+	return &ReadResponse{
+		Status:    this.status(),
+		Payload:   this.payload(),
+		Raw:       this.data,
+		Attempts:  this.attempts,
+		TotalWait: this.totalWait,
+	}
+}
+
+func (this *AtlasScientific) performReadRetry(waitTime time.Duration, policy RetryPolicy) (*rawResponse, error) {
+	this.waitForData(waitTime)
+
+	resp := &rawResponse{
+		data:      make([]byte, 64),
+		totalWait: waitTime,
+	}
+
+	for {
+		if e := this.read(resp.data); e != nil {
+			if resp.attempts == 0 {
+				return nil, e
 			}
+			return resp, e
+		}
+		resp.attempts++
 
-		} else {
-			return "", e
+		//this.GetContextLogger().WithField("data", data).Debug("Raw data read from device")
+
+		e := this.checkResponse(resp.data)
+		if e == nil {
+			return resp, nil
+		}
+
+		if e.code != ResponsePending || resp.attempts > policy.Retries {
+			return resp, e
+		}
+
+		delay := policy.RetryDelay
+		if delay <= 0 {
+			delay = waitTime
 		}
+
+		if policy.MaxWait > 0 && resp.totalWait+delay > policy.MaxWait {
+			return resp, e
+		}
+
+		this.GetContextLogger().WithFields(log.Fields{
+			"waitTime": delay,
+			"attempt":  resp.attempts,
+		}).Warn("Attempting re-read after additional wait time")
+
+		this.waitForData(delay)
+		resp.totalWait += delay
 	}
+}
 
-	trimData := bytes.Trim(data, "\x00")
+func (this *AtlasScientific) readRetryPolicy() RetryPolicy {
+	if this.ReadRetryPolicy == nil {
+		return DefaultRetryPolicy
+	}
 
-	//this.GetContextLogger().WithField("trimmedData", trimData).Debug("Trimmed data")
+	return *this.ReadRetryPolicy
+}
 
-	return string(trimData[1:]), nil
+func (this *AtlasScientific) calibrationRetryPolicy() RetryPolicy {
+	if this.CalibrationRetryPolicy == nil {
+		return DefaultRetryPolicy
+	}
+
+	return *this.CalibrationRetryPolicy
 }
 
-func (this *AtlasScientific) WriteReadParse(writeCommand string, waitTime time.Duration, parseRegex *regexp.Regexp) (map[string]string, error) {
-	if _, e := this.Write(writeCommand); e != nil {
-		return nil, e
+//waitForData blocks until the device should have a response ready.  When a
+//DataReadyNotifier is configured the wait ends as soon as the device signals,
+//with waitTime used as the upper bound.  Otherwise the full waitTime is slept.
+func (this *AtlasScientific) waitForData(waitTime time.Duration) {
+	if this.DataReady == nil {
+		time.Sleep(waitTime)
+		return
+	}
+
+	if e := this.DataReady.WaitDataReady(waitTime); e != nil {
+		this.GetContextLogger().WithField("error", e).Debug("Data ready wait failed, reading anyway")
 	}
+}
 
-	if data, e := this.PerformRead(waitTime); e != nil {
+func (this *AtlasScientific) WriteReadParse(writeCommand string, waitTime time.Duration, parseRegex *regexp.Regexp) (map[string]string, error) {
+	if data, e := this.WriteRead(writeCommand, waitTime); e != nil {
 		return nil, e
 	} else {
 		if valMap, e := FindStringSubmatchMap(parseRegex, data); e != nil {
@@ -311,22 +1138,257 @@ func (this *AtlasScientific) WriteReadParse(writeCommand string, waitTime time.D
 
 func (this *AtlasScientific) Write(data string) (int, error) {
 	byteData := []byte(data)
+	this.lastCommand = data
 
 	/* this.GetContextLogger().WithFields(log.Fields{
 		"data":     data,
 		"byteData": byteData,
 	}).Debug("Writing to device") */
 
-	return this.Connection.Write(this.Address, byteData)
+	if this.Transport != nil {
+		return this.Transport.Write(this.Address, byteData)
+	} else if this.Connection == nil {
+		return 0, ErrNotConnected
+	}
+
+	n, e := this.Connection.Write(this.Address, byteData)
+	if e != nil && this.reconnect(e) {
+		return this.Connection.Write(this.Address, byteData)
+	}
+
+	return n, e
+}
+
+func (this *AtlasScientific) read(data []byte) error {
+	if this.Transport != nil {
+		_, e := this.Transport.Read(this.Address, data)
+		return e
+	} else if this.Connection == nil {
+		return ErrNotConnected
+	}
+
+	_, e := this.Connection.Read(this.Address, data)
+	if e != nil && this.reconnect(e) {
+		_, e = this.Connection.Read(this.Address, data)
+	}
+
+	return e
+}
+
+//reconnect reopens Bus with exponential backoff after a transport error.  It
+//returns true if a new connection was established and the operation should be
+//retried.  Callers must hold Mtx.
+func (this *AtlasScientific) reconnect(cause error) bool {
+	if this.Reconnect == nil || this.Reconnect.Attempts < 1 || !isTransportError(cause) {
+		return false
+	}
+
+	logger := this.GetContextLogger().WithField("error", cause)
+	bus := this.Connection.Bus
+
+	if this.ownsConnection {
+		this.Connection.Close()
+	}
+
+	backoff := this.Reconnect.InitialBackoff
+
+	for attempt := 1; attempt <= this.Reconnect.Attempts; attempt++ {
+		logger.WithField("attempt", attempt).Warn("Reopening I2C bus after transport error")
+
+		if conn, e := i2c.NewI2C(bus); e == nil {
+			this.Connection = conn
+			this.Bus = bus
+			this.ownsConnection = true
+			return true
+		}
+
+		if attempt == this.Reconnect.Attempts {
+			break
+		}
+
+		time.Sleep(backoff)
+
+		backoff *= 2
+		if this.Reconnect.MaxBackoff > 0 && backoff > this.Reconnect.MaxBackoff {
+			backoff = this.Reconnect.MaxBackoff
+		}
+	}
+
+	logger.Error("Unable to reopen I2C bus")
+
+	return false
+}
+
+//isTransportError reports whether e indicates the bus itself failed, as opposed
+//to the device not acknowledging its address
+func isTransportError(e error) bool {
+	return errors.Is(e, syscall.EIO) || errors.Is(e, syscall.ENODEV)
+}
+
+//Connect opens the I2C bus numbered Bus if the device was constructed without a
+//connection
+func (this *AtlasScientific) Connect() error {
+	this.Mtx.Lock()
+	defer this.Mtx.Unlock()
+
+	if this.Connection != nil || this.Transport != nil {
+		return nil
+	}
+
+	if conn, e := i2c.NewI2C(this.Bus); e != nil {
+		return e
+	} else {
+		this.Connection = conn
+		this.ownsConnection = true
+		return nil
+	}
+}
+
+func (this *AtlasScientific) Connected() bool {
+	this.Mtx.Lock()
+	defer this.Mtx.Unlock()
+
+	return this.Connection != nil || this.Transport != nil
 }
 
 func (this *AtlasScientific) GetContextLogger() *log.Entry {
-	return log.WithFields(log.Fields{
+	entry := this.Logger
+
+	if entry == nil {
+		entry = log.NewEntry(log.StandardLogger())
+	}
+
+	if len(this.LogFields) > 0 {
+		entry = entry.WithFields(this.LogFields)
+	}
+
+	if this.Connection == nil {
+		return entry.WithFields(log.Fields{
+			"i2cBus":        this.Bus,
+			"deviceAddress": this.Address,
+		})
+	}
+
+	return entry.WithFields(log.Fields{
 		"i2cBus":        this.Connection.Bus,
 		"deviceAddress": this.Address,
 	})
 }
 
+//SelfTest takes readings until consecutive values settle within a tenth of the
+//tolerance, then compares the stabilized value against expected.  Intended to be
+//run with the probe sitting in a reference solution, e.g. pH 7 buffer.
+func SelfTest(sensor AtlasScientificSensor, expected float32, tolerance float32) (*SelfTestResult, error) {
+	if tolerance <= 0 {
+		return nil, errors.New(fmt.Sprintf("Invalid tolerance '%f'.  Must be greater than 0.", tolerance))
+	}
+
+	previous, e := sensor.GetValue()
+	if e != nil {
+		return nil, e
+	}
+
+	for i := 1; i < selfTestMaxReadings; i++ {
+		value, e := sensor.GetValue()
+		if e != nil {
+			return nil, e
+		}
+
+		if abs32(value-previous) <= tolerance/10 {
+			deviation := value - expected
+
+			return &SelfTestResult{
+				Expected:  expected,
+				Tolerance: tolerance,
+				Value:     value,
+				Deviation: deviation,
+				Passed:    abs32(deviation) <= tolerance,
+			}, nil
+		}
+
+		previous = value
+	}
+
+	return nil, errors.New(fmt.Sprintf("Readings did not stabilize after %d attempts.  Last value: %f", selfTestMaxReadings, previous))
+}
+
+//ReadAverage takes n readings, waiting interval between each, and returns their
+//mean, min, max and sample standard deviation
+func ReadAverage(ctx context.Context, sensor AtlasScientificSensor, n int, interval time.Duration) (*ReadingStats, error) {
+	if n < 1 {
+		return nil, errors.New(fmt.Sprintf("Invalid reading count '%d'.  Must be at least 1.", n))
+	}
+
+	values := make([]float64, 0, n)
+
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+
+		if v, e := sensor.GetValue(); e != nil {
+			return nil, e
+		} else {
+			values = append(values, float64(v))
+		}
+	}
+
+	return summarize(values), nil
+}
+
+//summarize computes statistics over a non-empty series of values
+func summarize(values []float64) *ReadingStats {
+	n := len(values)
+
+	stats := &ReadingStats{
+		Count: n,
+		Min:   float32(values[0]),
+		Max:   float32(values[0]),
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+		stats.Min = float32(math.Min(float64(stats.Min), v))
+		stats.Max = float32(math.Max(float64(stats.Max), v))
+	}
+
+	mean := sum / float64(n)
+	stats.Mean = float32(mean)
+
+	if n > 1 {
+		var squares float64
+		for _, v := range values {
+			squares += (v - mean) * (v - mean)
+		}
+		stats.StdDev = float32(math.Sqrt(squares / float64(n-1)))
+	}
+
+	return stats
+}
+
+func abs32(f float32) float32 {
+	if f < 0 {
+		return -f
+	}
+
+	return f
+}
+
+//ValidateAddress returns an error if address is outside the 7-bit I2C address
+//range supported by EZO circuits
+func ValidateAddress(address uint8) error {
+	if address < 1 || address > 127 {
+		return errors.New(fmt.Sprintf("Invalid address '%d'.  Must be between 1 and 127.", address))
+	}
+
+	return nil
+}
+
 func FindStringSubmatchMap(r *regexp.Regexp, s string) (map[string]string, error) {
 	captures := make(map[string]string)
 
@@ -345,23 +1407,47 @@ func FindStringSubmatchMap(r *regexp.Regexp, s string) (map[string]string, error
 	return captures, nil
 }
 
+//checkResponse wraps checkReadError, adding the command that was sent and the
+//firmware version (when known) to syntax errors since those almost always mean
+//the command is not supported by the firmware.
+func (this *AtlasScientific) checkResponse(data []byte) *ReadError {
+	e := checkReadError(data)
+
+	if e != nil && e.code == ResponseSyntaxError {
+		e.command = this.lastCommand
+		e.message = fmt.Sprintf("%s.  Command: '%s'", e.message, this.lastCommand)
+
+		if this.firmwareVersion > 0 {
+			e.message = fmt.Sprintf("%s\tFirmware version: %.2f", e.message, this.firmwareVersion)
+		}
+	}
+
+	return e
+}
+
 func checkReadError(data []byte) *ReadError {
-	switch data[0] {
-	case 1:
+	switch ResponseCode(data[0]) {
+	case ResponseSuccess:
 		return nil
-	case 2:
+	case ResponseSyntaxError:
+		message := "Syntax error"
+
+		if echo := bytes.Trim(data[1:], "\x00"); len(echo) > 0 {
+			message = fmt.Sprintf("%s: %s", message, echo)
+		}
+
 		return &ReadError{
-			status:  2,
-			message: "Read error",
+			code:    ResponseSyntaxError,
+			message: message,
 		}
-	case 254:
+	case ResponsePending:
 		return &ReadError{
-			status:  254,
+			code:    ResponsePending,
 			message: "Pending",
 		}
-	case 255:
+	case ResponseNoData:
 		return &ReadError{
-			status:  255,
+			code:    ResponseNoData,
 			message: "No Data",
 		}
 	}
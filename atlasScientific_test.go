@@ -0,0 +1,84 @@
+package atlasScientific
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeTransport answers each command from responses
+type fakeTransport struct {
+	responses map[string]string
+	last      string
+}
+
+func (this *fakeTransport) Write(address uint8, data []byte) (int, error) {
+	this.last = string(data)
+	return len(data), nil
+}
+
+func (this *fakeTransport) Read(address uint8, data []byte) (int, error) {
+	data[0] = byte(ResponseSuccess)
+	copy(data[1:], this.responses[this.last])
+	return len(data), nil
+}
+
+type immediateDataReady struct{}
+
+func (this immediateDataReady) WaitDataReady(timeout time.Duration) error {
+	return nil
+}
+
+func TestValidateTempCompensation(t *testing.T) {
+	custom := Bounds{Min: 5, Max: 40}
+
+	cases := []struct {
+		name         string
+		tempC        float32
+		bounds       *Bounds
+		rejectZero   bool
+		err          bool
+		zeroRejected bool
+		message      string
+	}{
+		{name: "in range", tempC: 25},
+		{name: "below freezing", tempC: -2},
+		{name: "zero allowed", tempC: 0},
+		{name: "zero rejected", tempC: 0, rejectZero: true, err: true, zeroRejected: true, message: "source may be unset"},
+		{name: "zero outside custom range", tempC: 0, bounds: &custom, err: true, message: "outside of range"},
+		{name: "too hot", tempC: 200, err: true, message: "outside of range"},
+	}
+
+	for _, c := range cases {
+		sensor := AtlasScientific{TempCompensationRange: c.bounds, RejectZeroTempCompensation: c.rejectZero}
+		e := sensor.validateTempCompensation(c.tempC)
+
+		if !c.err {
+			if e != nil {
+				t.Errorf("%s: unexpected error: %v", c.name, e)
+			}
+			continue
+		}
+
+		if tempErr, ok := e.(*TempCompensationError); !ok {
+			t.Errorf("%s: expected a TempCompensationError, got %v", c.name, e)
+		} else if tempErr.ZeroRejected != c.zeroRejected {
+			t.Errorf("%s: expected ZeroRejected %v", c.name, c.zeroRejected)
+		} else if !strings.Contains(tempErr.Error(), c.message) {
+			t.Errorf("%s: expected message containing %q, got %q", c.name, c.message, tempErr.Error())
+		}
+	}
+}
+
+func TestGetTempCompensationNegative(t *testing.T) {
+	sensor := AtlasScientific{
+		Transport: &fakeTransport{responses: map[string]string{"T,?": "?T,-2.000"}},
+		DataReady: immediateDataReady{},
+	}
+
+	if tempC, e := sensor.GetTempCompensation(); e != nil {
+		t.Fatalf("unexpected error: %v", e)
+	} else if tempC != -2 {
+		t.Errorf("expected -2, got %f", tempC)
+	}
+}
@@ -0,0 +1,168 @@
+package bme280
+
+import (
+	"errors"
+	"fmt"
+	"github.com/idahoakl/go-atlasScientific"
+	"github.com/idahoakl/go-i2c"
+	"sync"
+	"time"
+)
+
+const (
+	chipIdRegister   = 0xD0
+	calRegister      = 0x88
+	ctrlMeasRegister = 0xF4
+	dataRegister     = 0xF7
+
+	chipId = 0x60
+
+	//Temperature and pressure oversampling x1, forced mode
+	ctrlMeasForced = 0x25
+
+	measurementTime = 10 * time.Millisecond
+)
+
+//BME280 provides temperature and pressure compensation values from a Bosch
+//BME280 sensor.  Salinity is not available.
+type BME280 struct {
+	Connection *i2c.I2C
+	Address    uint8
+	Mtx        sync.Mutex
+
+	cal *calibration
+}
+
+type calibration struct {
+	t1             uint16
+	t2, t3         int16
+	p1             uint16
+	p2, p3, p4, p5 int16
+	p6, p7, p8, p9 int16
+}
+
+var _ atlasScientific.CompensationProvider = (*BME280)(nil)
+
+func New(address uint8, connection *i2c.I2C) (*BME280, error) {
+	return &BME280{
+		Connection: connection,
+		Address:    address,
+	}, nil
+}
+
+//Init verifies the chip id and loads the factory calibration
+func (this *BME280) Init() error {
+	this.Mtx.Lock()
+	defer this.Mtx.Unlock()
+
+	return this.init()
+}
+
+func (this *BME280) Temperature() (float32, error) {
+	if t, _, e := this.measure(); e != nil {
+		return atlasScientific.ERROR_VALUE, e
+	} else {
+		return float32(t), nil
+	}
+}
+
+func (this *BME280) Pressure() (float32, error) {
+	if _, p, e := this.measure(); e != nil {
+		return atlasScientific.ERROR_VALUE, e
+	} else {
+		return float32(p / 1000), nil
+	}
+}
+
+func (this *BME280) Salinity() (float32, error) {
+	return atlasScientific.ERROR_VALUE, atlasScientific.ErrCompensationUnavailable
+}
+
+func (this *BME280) init() error {
+	id := make([]byte, 1)
+	if e := this.readRegister(chipIdRegister, id); e != nil {
+		return e
+	}
+
+	if id[0] != chipId {
+		return errors.New(fmt.Sprintf("Unexpected chip id 0x%X.  Expected 0x%X", id[0], chipId))
+	}
+
+	data := make([]byte, 24)
+	if e := this.readRegister(calRegister, data); e != nil {
+		return e
+	}
+
+	u16 := func(i int) uint16 { return uint16(data[i]) | uint16(data[i+1])<<8 }
+	s16 := func(i int) int16 { return int16(u16(i)) }
+
+	this.cal = &calibration{
+		t1: u16(0), t2: s16(2), t3: s16(4),
+		p1: u16(6), p2: s16(8), p3: s16(10), p4: s16(12), p5: s16(14),
+		p6: s16(16), p7: s16(18), p8: s16(20), p9: s16(22),
+	}
+
+	return nil
+}
+
+//measure triggers a forced-mode measurement and returns temperature in C and
+//pressure in Pa using the floating point compensation from the datasheet
+func (this *BME280) measure() (float64, float64, error) {
+	this.Mtx.Lock()
+	defer this.Mtx.Unlock()
+
+	if this.cal == nil {
+		if e := this.init(); e != nil {
+			return 0, 0, e
+		}
+	}
+
+	if _, e := this.Connection.Write(this.Address, []byte{ctrlMeasRegister, ctrlMeasForced}); e != nil {
+		return 0, 0, e
+	}
+
+	time.Sleep(measurementTime)
+
+	data := make([]byte, 6)
+	if e := this.readRegister(dataRegister, data); e != nil {
+		return 0, 0, e
+	}
+
+	adcP := float64(int32(data[0])<<12 | int32(data[1])<<4 | int32(data[2])>>4)
+	adcT := float64(int32(data[3])<<12 | int32(data[4])<<4 | int32(data[5])>>4)
+	c := this.cal
+
+	var1 := (adcT/16384 - float64(c.t1)/1024) * float64(c.t2)
+	var2 := adcT/131072 - float64(c.t1)/8192
+	var2 = var2 * var2 * float64(c.t3)
+	tFine := var1 + var2
+	temp := tFine / 5120
+
+	var1 = tFine/2 - 64000
+	var2 = var1 * var1 * float64(c.p6) / 32768
+	var2 = var2 + var1*float64(c.p5)*2
+	var2 = var2/4 + float64(c.p4)*65536
+	var1 = (float64(c.p3)*var1*var1/524288 + float64(c.p2)*var1) / 524288
+	var1 = (1 + var1/32768) * float64(c.p1)
+
+	if var1 == 0 {
+		return 0, 0, errors.New("Invalid pressure calibration data")
+	}
+
+	p := 1048576 - adcP
+	p = (p - var2/4096) * 6250 / var1
+	var1 = float64(c.p9) * p * p / 2147483648
+	var2 = p * float64(c.p8) / 32768
+	p = p + (var1+var2+float64(c.p7))/16
+
+	return temp, p, nil
+}
+
+func (this *BME280) readRegister(register byte, data []byte) error {
+	if _, e := this.Connection.Write(this.Address, []byte{register}); e != nil {
+		return e
+	}
+
+	_, e := this.Connection.Read(this.Address, data)
+	return e
+}
@@ -1,19 +1,38 @@
 package conductivity
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	log "github.com/Sirupsen/logrus"
 	"github.com/idahoakl/go-atlasScientific"
 	"github.com/idahoakl/go-i2c"
+	"math"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
+var _ atlasScientific.Sensor = (*Conductivity)(nil)
+var _ atlasScientific.Snapshotter = (*Conductivity)(nil)
+var _ atlasScientific.LastReader = (*Conductivity)(nil)
+
 type Conductivity struct {
 	atlasScientific.AtlasScientific
 	DefaultMeasurement ConductivityMeasurement
+	//TempCoefficient overrides the firmware's fixed StandardTempCoefficient for
+	//EC and TDS readings, as a fraction per degree C.  The circuit offers no way
+	//to change its coefficient, so readings are re-compensated in software using
+	//the temperature compensation in effect, which is queried from the circuit
+	//when not yet known.  Readings are marked suspect if it cannot be
+	//determined.  nil leaves readings as reported.
+	TempCoefficient *float32
+
+	//outputParams caches the last queried output parameters so coalesced reads
+	//need no device I/O.  Guarded by Mtx.
+	outputParams []ConductivityMeasurement
 }
 
 type ConductivityMeasurement int
@@ -27,6 +46,27 @@ const (
 
 type CalibrationPoint string
 
+//StandardTempCoefficient is the typical change in a conductivity standard's
+//value per degree C, as a fraction of its value at 25C
+const StandardTempCoefficient = 0.02
+
+//Common EC to TDS conversion factors that ppm calibration standards are
+//labelled on
+const (
+	NaClTDSFactor = 0.5
+	KClTDSFactor  = 0.7
+)
+
+//CalibrationUnit is the unit a calibration standard's value is labelled in
+type CalibrationUnit int
+
+const (
+	MicroSiemens CalibrationUnit = iota
+	MilliSiemens
+	//PPM values need the conversion factor the standard is labelled on
+	PPM
+)
+
 const (
 	Dry  CalibrationPoint = "dry"
 	One  CalibrationPoint = "one"
@@ -50,57 +90,321 @@ var (
 		"S":   Salinity,
 		"SG":  SpecificGravity,
 	}
+
+	descriptions = map[ConductivityMeasurement]atlasScientific.Description{
+		EC:              {Type: "EC", Unit: "uS/cm", ValidRange: atlasScientific.Bounds{Min: 0.07, Max: 500000}},
+		TDS:             {Type: "TDS", Unit: "ppm", ValidRange: atlasScientific.Bounds{Min: 0, Max: 500000}},
+		Salinity:        {Type: "S", Unit: "PSU", ValidRange: atlasScientific.Bounds{Min: 0, Max: 42}},
+		SpecificGravity: {Type: "SG", Unit: "", ValidRange: atlasScientific.Bounds{Min: 1, Max: 1.3}},
+	}
+
+	calibrationSpecs = []atlasScientific.CalibrationSpec{
+		{Point: string(Dry), Delay: 2000 * time.Millisecond},
+		{Point: string(One), RequiresValue: true, ValidRange: descriptions[EC].ValidRange, Delay: 1500 * time.Millisecond},
+		{Point: string(Low), RequiresValue: true, ValidRange: descriptions[EC].ValidRange, Delay: 1500 * time.Millisecond},
+		{Point: string(High), RequiresValue: true, ValidRange: descriptions[EC].ValidRange, Delay: 1500 * time.Millisecond},
+	}
+
+	//Dry calibration in particular can run past its documented wait time
+	calibrationRetryPolicy = atlasScientific.RetryPolicy{
+		Retries:    3,
+		RetryDelay: 300 * time.Millisecond,
+		MaxWait:    4000 * time.Millisecond,
+	}
 )
 
 func New(address uint8, connection *i2c.I2C, defaultMeasurement ConductivityMeasurement) (*Conductivity, error) {
+	if e := atlasScientific.ValidateAddress(address); e != nil {
+		return nil, e
+	}
+
+	calRetry := calibrationRetryPolicy
+
 	return &Conductivity{
 		DefaultMeasurement: defaultMeasurement,
 		AtlasScientific: atlasScientific.AtlasScientific{
-			Connection: connection,
-			Address:    address,
+			Connection:             connection,
+			Address:                address,
+			CalibrationRetryPolicy: &calRetry,
 		},
 	}, nil
 }
 
+//NewLazy creates a Conductivity without opening the bus.  Connect must be called
+//before any commands are issued.
+func NewLazy(address uint8, bus int, defaultMeasurement ConductivityMeasurement) (*Conductivity, error) {
+	if c, e := New(address, nil, defaultMeasurement); e != nil {
+		return nil, e
+	} else {
+		c.Bus = bus
+		return c, nil
+	}
+}
+
 func (this *Conductivity) Init() error {
 	return this.defaultOutputParameters()
 }
 
 func (this *Conductivity) GetValue() (float32, error) {
-	if valMap, e := this.GetAllValues(); e != nil {
+	if reading, e := this.Read(); e != nil {
 		return atlasScientific.ERROR_VALUE, e
 	} else {
-		return valMap[this.DefaultMeasurement], nil
+		return reading.Value, nil
 	}
 }
 
-func (this *Conductivity) GetAllValues() (map[ConductivityMeasurement]float32, error) {
+//Read returns a reading of DefaultMeasurement
+func (this *Conductivity) Read() (atlasScientific.Reading, error) {
+	if values, complete, e := this.getAllValues(); e != nil {
+		return atlasScientific.Reading{}, e
+	} else {
+		return this.recordValues(values, complete)
+	}
+}
+
+//ReadCompensated takes a reading of DefaultMeasurement compensated to the
+//provider's temperature, using the combined RT command where the firmware
+//supports it
+func (this *Conductivity) ReadCompensated(provider atlasScientific.CompensationProvider) (atlasScientific.Reading, error) {
 	if outputParams, e := this.GetOutputParameters(); e != nil {
+		return atlasScientific.Reading{}, e
+	} else if rawValue, e := this.ReadRawCompensated(provider); e != nil {
+		return atlasScientific.Reading{}, e
+	} else if values, complete, e := this.parseAllValues(outputParams, rawValue); e != nil {
+		return atlasScientific.Reading{}, e
+	} else {
+		return this.recordValues(values, complete)
+	}
+}
+
+//recordValues records DefaultMeasurement from values as the latest reading
+func (this *Conductivity) recordValues(values map[ConductivityMeasurement]float32, complete bool) (atlasScientific.Reading, error) {
+	if v, e := this.defaultValue(values); e != nil {
+		return atlasScientific.Reading{}, e
+	} else {
+		quality := atlasScientific.QualityGood
+		if !complete {
+			quality = atlasScientific.QualitySuspect
+		}
+
+		return this.RecordReading(v, this.Describe().ValidRange, quality), nil
+	}
+}
+
+//Verify checks that the device at Address identifies as a conductivity circuit
+func (this *Conductivity) Verify() error {
+	return this.VerifyDeviceType(descriptions[EC].Type)
+}
+
+//Describe returns the description of DefaultMeasurement
+func (this *Conductivity) Describe() atlasScientific.Description {
+	return descriptions[this.DefaultMeasurement]
+}
+
+//Calibrate performs calibration for one of CalibrationSpecs.  Valid calPoint
+//values: dry, one, low, high
+func (this *Conductivity) Calibrate(calPoint string, value float32) error {
+	if spec, e := atlasScientific.FindCalibrationSpec(calibrationSpecs, calPoint); e != nil {
+		return e
+	} else {
+		return this.AtlasScientific.Calibrate(spec, value)
+	}
+}
+
+func (this *Conductivity) CalibrationSpecs() []atlasScientific.CalibrationSpec {
+	return calibrationSpecs
+}
+
+//ReadAverage takes n readings of DefaultMeasurement interval apart and
+//summarizes them
+func (this *Conductivity) ReadAverage(ctx context.Context, n int, interval time.Duration) (*atlasScientific.ReadingStats, error) {
+	return atlasScientific.ReadAverage(ctx, this, n, interval)
+}
+
+//SelfTest compares a stabilized reading of DefaultMeasurement against a
+//standard solution of known value
+func (this *Conductivity) SelfTest(expected float32, tolerance float32) (*atlasScientific.SelfTestResult, error) {
+	return atlasScientific.SelfTest(this, expected, tolerance)
+}
+
+//Snapshot adds the probe type and enabled output parameters to the common
+//device configuration
+func (this *Conductivity) Snapshot() (*atlasScientific.Snapshot, error) {
+	if snapshot, e := this.AtlasScientific.Snapshot(); e != nil {
 		return nil, e
-	} else if rawValue, e := this.GetRawValue(); e != nil {
+	} else if probeType, e := this.GetProbeType(); e != nil {
+		return nil, e
+	} else if outputParams, e := this.GetOutputParameters(); e != nil {
 		return nil, e
 	} else {
-		data := strings.Split(rawValue, ",")
+		snapshot.ProbeType = probeType
 
-		if len(data) != len(outputParams) {
-			return nil,
-				errors.New(
-					fmt.Sprintf("Output param count mis-match.  Output params: %v\tData values: %v\tRaw string: %s",
-						outputParams, data, rawValue))
+		for _, m := range outputParams {
+			snapshot.OutputParameters = append(snapshot.OutputParameters, conductivityMeasurementToOutputParam[m])
 		}
 
-		values := make(map[ConductivityMeasurement]float32)
+		return snapshot, nil
+	}
+}
 
-		for i, k := range outputParams {
-			if f, e := strconv.ParseFloat(data[i], 32); e != nil {
-				return nil, e
-			} else {
-				values[k] = float32(f)
-			}
+//GetAllValues returns every enabled output parameter.  If the reading does not
+//match the enabled parameters, which happens briefly after changing them, the
+//parameters are re-queried and whatever can be parsed is returned with a warning.
+func (this *Conductivity) GetAllValues() (map[ConductivityMeasurement]float32, error) {
+	values, _, e := this.getAllValues()
+	return values, e
+}
+
+func (this *Conductivity) getAllValues() (map[ConductivityMeasurement]float32, bool, error) {
+	if outputParams, rawValue, ok := this.coalescedValue(); ok {
+		return this.parseAllValues(outputParams, rawValue)
+	}
+
+	if outputParams, e := this.GetOutputParameters(); e != nil {
+		return nil, false, e
+	} else if rawValue, e := this.GetRawValue(); e != nil {
+		return nil, false, e
+	} else {
+		return this.parseAllValues(outputParams, rawValue)
+	}
+}
+
+//coalescedValue returns the cached output parameters and raw value when a read
+//within CoalesceWindow can be answered without querying the device
+func (this *Conductivity) coalescedValue() ([]ConductivityMeasurement, string, bool) {
+	this.Mtx.Lock()
+	defer this.Mtx.Unlock()
+
+	if this.outputParams == nil {
+		return nil, "", false
+	}
+
+	if rawValue, ok := this.CoalescedValue(); ok {
+		return this.outputParams, rawValue, true
+	}
+
+	return nil, "", false
+}
+
+//parseAllValues parses rawValue against outputParams, re-querying the output
+//parameters on a mismatch, and applies TempCoefficient
+func (this *Conductivity) parseAllValues(outputParams []ConductivityMeasurement, rawValue string) (map[ConductivityMeasurement]float32, bool, error) {
+	values, complete, e := parseValues(outputParams, rawValue)
+
+	if e != nil || !complete {
+		if outputParams, e = this.GetOutputParameters(); e != nil {
+			return nil, false, e
 		}
 
-		return values, nil
+		values, complete, e = parseValues(outputParams, rawValue)
+	}
+
+	if e != nil {
+		return nil, false, e
+	}
+
+	if !complete {
+		this.GetContextLogger().WithFields(log.Fields{
+			"outputParams": outputParams,
+			"rawValue":     rawValue,
+			"values":       values,
+		}).Warn("Output params do not match reading, returning partial values")
 	}
+
+	if !this.applyTempCoefficient(values) {
+		complete = false
+	}
+
+	return values, complete, nil
+}
+
+//applyTempCoefficient converts EC and TDS values compensated with the
+//firmware's StandardTempCoefficient to TempCoefficient.  The circuit's
+//temperature compensation is queried if it is not yet known.  It returns false
+//when the values could not be converted.
+func (this *Conductivity) applyTempCoefficient(values map[ConductivityMeasurement]float32) bool {
+	if this.TempCoefficient == nil {
+		return true
+	}
+
+	tempC, e := this.circuitTempCompensation()
+	if e != nil {
+		this.GetContextLogger().WithField("error", e).Warn("Unable to determine temperature compensation, TempCoefficient not applied")
+		return false
+	}
+
+	delta := tempC - 25
+	factor := (1 + StandardTempCoefficient*delta) / (1 + *this.TempCoefficient*delta)
+
+	for _, m := range []ConductivityMeasurement{EC, TDS} {
+		if v, ok := values[m]; ok {
+			values[m] = v * factor
+		}
+	}
+
+	return true
+}
+
+//ReadDebug takes a reading and returns the raw response alongside the parsed
+//value of DefaultMeasurement.  The DebugReading is non-nil whenever data was
+//read, even on error.
+func (this *Conductivity) ReadDebug() (*atlasScientific.DebugReading, error) {
+	if outputParams, e := this.GetOutputParameters(); e != nil {
+		return nil, e
+	} else if debug, e := this.GetRawValueDebug(); e != nil {
+		return debug, e
+	} else if values, complete, e := this.parseAllValues(outputParams, debug.Payload); e != nil {
+		return debug, e
+	} else if reading, e := this.recordValues(values, complete); e != nil {
+		return debug, e
+	} else {
+		debug.Value = reading.Value
+		return debug, nil
+	}
+}
+
+//parseValues maps the comma separated fields of rawValue onto outputParams,
+//skipping blank fields.  complete is false if any field or param was left over
+//or could not be parsed.
+func parseValues(outputParams []ConductivityMeasurement, rawValue string) (values map[ConductivityMeasurement]float32, complete bool, err error) {
+	data := strings.Split(rawValue, ",")
+
+	values = make(map[ConductivityMeasurement]float32)
+	complete = len(data) == len(outputParams)
+
+	//Fields are matched to outputParams by position, so a blank field skips
+	//its parameter rather than shifting later values onto it
+	for i, k := range outputParams {
+		if i >= len(data) {
+			break
+		}
+
+		if field := strings.TrimSpace(data[i]); field == "" {
+			complete = false
+		} else if f, e := strconv.ParseFloat(field, 32); e != nil {
+			complete = false
+		} else {
+			values[k] = float32(f)
+		}
+	}
+
+	if len(values) == 0 {
+		return nil, false,
+			errors.New(
+				fmt.Sprintf("Unable to parse any values.  Output params: %v\tData values: %v\tRaw string: %s",
+					outputParams, data, rawValue))
+	}
+
+	return values, complete, nil
+}
+
+func (this *Conductivity) defaultValue(values map[ConductivityMeasurement]float32) (float32, error) {
+	if v, ok := values[this.DefaultMeasurement]; ok {
+		return v, nil
+	}
+
+	return atlasScientific.ERROR_VALUE,
+		errors.New(fmt.Sprintf("Default measurement %v missing from reading.  Values: %v", this.DefaultMeasurement, values))
 }
 
 //Example instruction sequence:
@@ -131,6 +435,8 @@ func (this *Conductivity) GetOutputParameters() ([]ConductivityMeasurement, erro
 			}
 		}
 
+		this.outputParams = outputParams
+
 		return outputParams, nil
 	}
 }
@@ -143,6 +449,8 @@ func (this *Conductivity) OutputParameters(outputParams map[ConductivityMeasurem
 	this.Mtx.Lock()
 	defer this.Mtx.Unlock()
 
+	this.outputParams = nil
+
 	for key, value := range outputParams {
 		p, ok := conductivityMeasurementToOutputParam[key]
 
@@ -158,11 +466,7 @@ func (this *Conductivity) OutputParameters(outputParams map[ConductivityMeasurem
 			valStr = "1"
 		}
 
-		if _, e := this.Write(fmt.Sprintf("O,%s,%s", p, valStr)); e != nil {
-			return e
-		}
-
-		if _, e := this.PerformRead(300 * time.Millisecond); e != nil {
+		if e := this.PerformCommand(fmt.Sprintf("O,%s,%s", p, valStr), 300*time.Millisecond); e != nil {
 			return e
 		}
 	}
@@ -201,45 +505,181 @@ func (this *Conductivity) ProbeType(probeType float32) error {
 		return errors.New(fmt.Sprintf("Invalid probe type '%f'.  Must be between 0.1 and 10.", probeType))
 	}
 
-	if _, e := this.Write(fmt.Sprintf("K,%f", probeType)); e != nil {
+	return this.PerformCommand(fmt.Sprintf("K,%f", probeType), 300*time.Millisecond)
+}
+
+//Example instruction sequence:
+//	Write: CAL,low,210
+//	Wait: 1500ms (2000ms for dry)
+//	Read: <successful read, no data>
+func (this *Conductivity) Calibration(calPoint CalibrationPoint, ecValue float32) error {
+	return this.Calibrate(string(calPoint), ecValue)
+}
+
+//CalibrationInUnit calibrates with a standard labelled in unit, converting
+//value to microsiemens before it is sent.  tdsFactor is required for PPM and
+//ignored otherwise; see ToMicroSiemens.
+func (this *Conductivity) CalibrationInUnit(calPoint CalibrationPoint, value float32, unit CalibrationUnit, tdsFactor float32) error {
+	if ecValue, e := ToMicroSiemens(value, unit, tdsFactor); e != nil {
 		return e
+	} else {
+		return this.Calibration(calPoint, ecValue)
 	}
+}
 
-	if _, e := this.PerformRead(300 * time.Millisecond); e != nil {
+//ToMicroSiemens converts a conductivity value in unit to microsiemens.  ppm
+//standards are labelled on different scales, so tdsFactor, the EC to TDS
+//factor printed on the standard (commonly NaClTDSFactor or KClTDSFactor), must
+//be given for PPM.  It is ignored for other units.
+func ToMicroSiemens(value float32, unit CalibrationUnit, tdsFactor float32) (float32, error) {
+	switch unit {
+	case MicroSiemens:
+		return value, nil
+	case MilliSiemens:
+		return value * 1000, nil
+	case PPM:
+		if tdsFactor <= 0 {
+			return 0, errors.New(fmt.Sprintf("Invalid TDS conversion factor '%f'.  A factor greater than 0 is required for ppm.", tdsFactor))
+		}
+
+		return value / tdsFactor, nil
+	}
+
+	return 0, errors.New(fmt.Sprintf("Unknown calibration unit: %d", unit))
+}
+
+//TempCorrectedCalibration calibrates using a standard whose label value is
+//referenced to 25C (e.g. 1413 microsiemens) while the solution is at tempC.  The
+//circuit reports values compensated from its own temperature setting, so the
+//value sent depends on it: with T at 25C the label is corrected to what the
+//standard reads at tempC, and with T already set to tempC the label is sent
+//as is.  The circuit's setting is queried if it is not already known.
+func (this *Conductivity) TempCorrectedCalibration(calPoint CalibrationPoint, ecValueAt25C float32, tempC float32) error {
+	if circuitTempC, e := this.circuitTempCompensation(); e != nil {
 		return e
+	} else {
+		return this.Calibration(calPoint, CalibrationValueAt(ecValueAt25C, tempC, circuitTempC))
 	}
+}
 
-	return nil
+//CalibrationValueAt returns the value a circuit compensating at circuitTempC
+//should be calibrated to for a standard labelled with valueAt25C at tempC
+func CalibrationValueAt(valueAt25C float32, tempC float32, circuitTempC float32) float32 {
+	return StandardValueAt(valueAt25C, tempC) / (1 + StandardTempCoefficient*(circuitTempC-25))
 }
 
-//Example instruction sequence:
-//	Write: CAL,low,210
-//	Wait: 1300ms (2000ms for dry)
-//	Read: <successful read, no data>
-func (this *Conductivity) Calibration(calPoint CalibrationPoint, ecValue float32) error {
-	this.Mtx.Lock()
-	defer this.Mtx.Unlock()
+//circuitTempCompensation returns the temperature compensation in effect,
+//querying the circuit when it has not been set or read yet
+func (this *Conductivity) circuitTempCompensation() (float32, error) {
+	if tempC := this.CurrentCompensation().Temperature; tempC != nil {
+		return *tempC, nil
+	}
 
-	var calStr string
-	var calTime time.Duration
+	return this.GetTempCompensation()
+}
 
-	if calPoint == Dry {
-		calStr = "CAL,dry"
-		calTime = 2000 * time.Millisecond
-	} else {
-		calStr = fmt.Sprintf("CAL,%s,%d", calPoint, int(ecValue))
-		calTime = 1500 * time.Millisecond
+//StandardValueAt returns the conductivity at tempC of a standard labelled with
+//valueAt25C, using the linear StandardTempCoefficient
+func StandardValueAt(valueAt25C float32, tempC float32) float32 {
+	return valueAt25C * (1 + StandardTempCoefficient*(tempC-25))
+}
+
+//ECBoundsForProbeType returns the plausible EC range in microsiemens for a probe
+//with the given K value, for use as Bounds when DefaultMeasurement is EC
+func ECBoundsForProbeType(probeType float32) atlasScientific.Bounds {
+	switch {
+	case probeType < 1:
+		return atlasScientific.Bounds{Min: 0.07, Max: 50000}
+	case probeType < 10:
+		return atlasScientific.Bounds{Min: 5, Max: 200000}
+	default:
+		return atlasScientific.Bounds{Min: 10, Max: 500000}
 	}
+}
 
-	if _, e := this.Write(calStr); e != nil {
-		return e
+//StandardProbeTypes are the K values of Atlas Scientific conductivity probes
+var StandardProbeTypes = []float32{0.1, 1, 10}
+
+//ProbeTypeRecommendation is the result of comparing observed EC readings against
+//the range of the configured probe type
+type ProbeTypeRecommendation struct {
+	Current     float32
+	Recommended float32
+	//Misconfigured is set when the readings indicate the configured K value does
+	//not match the probe, e.g. a K0.1 probe configured as K1.0
+	Misconfigured bool
+}
+
+//RecommendProbeType suggests a K value for a series of EC readings in
+//microsiemens, given the currently configured probeType.
+//
+//A wrong K value scales every reading by configured K / actual K, which cannot
+//be told apart from a real change in conductivity.  referenceEC is the known EC
+//of the solution being measured, such as a calibration standard, and lets a
+//mis-scaled probe be detected.  Pass 0 when it is unknown; only readings at or
+//beyond the edge of the configured range are then flagged.
+func RecommendProbeType(probeType float32, readings []float32, referenceEC float32) (*ProbeTypeRecommendation, error) {
+	if len(readings) == 0 {
+		return nil, errors.New("At least one reading is required")
+	} else if probeType <= 0 {
+		return nil, errors.New(fmt.Sprintf("Invalid probe type '%f'.  Must be greater than 0.", probeType))
 	}
 
-	if _, e := this.PerformRead(calTime); e != nil {
-		return e
+	sorted := append([]float32(nil), readings...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	median := sorted[len(sorted)/2]
+
+	rec := &ProbeTypeRecommendation{
+		Current:     probeType,
+		Recommended: probeType,
 	}
 
-	return nil
+	if referenceEC > 0 && median > 0 {
+		//Readings are scaled by configured K / actual K, so the reference
+		//recovers the actual K
+		actual := nearestProbeType(probeType * referenceEC / median)
+
+		if ratio := actual / probeType; ratio > 2 || ratio < 0.5 {
+			rec.Recommended = actual
+			rec.Misconfigured = true
+			return rec, nil
+		}
+	}
+
+	if !withinProbeRange(probeType, sorted) {
+		rec.Misconfigured = true
+
+		for _, k := range StandardProbeTypes {
+			if withinProbeRange(k, sorted) {
+				rec.Recommended = k
+				break
+			}
+		}
+	}
+
+	return rec, nil
+}
+
+//withinProbeRange reports whether the sorted readings sit inside the range of
+//probeType, clear of its edges
+func withinProbeRange(probeType float32, sorted []float32) bool {
+	bounds := ECBoundsForProbeType(probeType)
+	return sorted[0] > bounds.Min*2 && sorted[len(sorted)-1] < bounds.Max*0.95
+}
+
+//nearestProbeType returns the standard K value closest to k on a log scale
+func nearestProbeType(k float32) float32 {
+	nearest := StandardProbeTypes[0]
+	best := math.Inf(1)
+
+	for _, candidate := range StandardProbeTypes {
+		if d := math.Abs(math.Log10(float64(k / candidate))); d < best {
+			nearest = candidate
+			best = d
+		}
+	}
+
+	return nearest
 }
 
 func (this *Conductivity) defaultOutputParameters() error {
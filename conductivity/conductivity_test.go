@@ -0,0 +1,308 @@
+package conductivity
+
+import (
+	"github.com/idahoakl/go-atlasScientific"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func near(a float32, b float32) bool {
+	return math.Abs(float64(a-b)) < 0.01
+}
+
+func TestCalibrationValueAt(t *testing.T) {
+	cases := []struct {
+		name         string
+		tempC        float32
+		circuitTempC float32
+		expected     float32
+	}{
+		{name: "circuit at 25C corrects label", tempC: 18, circuitTempC: 25, expected: 1215.18},
+		{name: "circuit at solution temperature sends label", tempC: 18, circuitTempC: 18, expected: 1413},
+		{name: "solution at 25C", tempC: 25, circuitTempC: 25, expected: 1413},
+		{name: "circuit at another temperature", tempC: 18, circuitTempC: 20, expected: 1350.2},
+	}
+
+	for _, c := range cases {
+		if v := CalibrationValueAt(1413, c.tempC, c.circuitTempC); !near(v, c.expected) {
+			t.Errorf("%s: expected %f, got %f", c.name, c.expected, v)
+		}
+	}
+}
+
+func TestRecommendProbeType(t *testing.T) {
+	cases := []struct {
+		name          string
+		probeType     float32
+		readings      []float32
+		referenceEC   float32
+		recommended   float32
+		misconfigured bool
+	}{
+		{
+			name:        "readings well inside range",
+			probeType:   1,
+			readings:    []float32{1400, 1410, 1415},
+			recommended: 1,
+		},
+		{
+			name:          "K0.1 probe configured as K1 reading a 1413 standard",
+			probeType:     1,
+			readings:      []float32{14100, 14130, 14150},
+			referenceEC:   1413,
+			recommended:   0.1,
+			misconfigured: true,
+		},
+		{
+			name:          "K10 probe configured as K1 reading an 80000 standard",
+			probeType:     1,
+			readings:      []float32{7990, 8000, 8010},
+			referenceEC:   80000,
+			recommended:   10,
+			misconfigured: true,
+		},
+		{
+			name:        "reference matches readings",
+			probeType:   0.1,
+			readings:    []float32{84, 84.2, 83.9},
+			referenceEC: 84,
+			recommended: 0.1,
+		},
+		{
+			name:        "mis-scaled readings without a reference are not detectable",
+			probeType:   1,
+			readings:    []float32{14100, 14130, 14150},
+			recommended: 1,
+		},
+		{
+			name:          "readings at the top of the K0.1 range",
+			probeType:     0.1,
+			readings:      []float32{49000, 49500, 50000},
+			recommended:   1,
+			misconfigured: true,
+		},
+		{
+			name:          "readings at the bottom of the K1 range",
+			probeType:     1,
+			readings:      []float32{3, 4, 5},
+			recommended:   0.1,
+			misconfigured: true,
+		},
+		{
+			name:          "readings beyond K1 range",
+			probeType:     1,
+			readings:      []float32{250000, 260000},
+			recommended:   10,
+			misconfigured: true,
+		},
+	}
+
+	for _, c := range cases {
+		if rec, e := RecommendProbeType(c.probeType, c.readings, c.referenceEC); e != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, e)
+		} else if rec.Recommended != c.recommended || rec.Misconfigured != c.misconfigured {
+			t.Errorf("%s: expected K%v misconfigured %v, got K%v misconfigured %v",
+				c.name, c.recommended, c.misconfigured, rec.Recommended, rec.Misconfigured)
+		}
+	}
+
+	if _, e := RecommendProbeType(1, nil, 0); e == nil {
+		t.Error("expected an error for no readings")
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	all := []ConductivityMeasurement{EC, TDS, Salinity, SpecificGravity}
+
+	cases := []struct {
+		name     string
+		params   []ConductivityMeasurement
+		raw      string
+		expected map[ConductivityMeasurement]float32
+		complete bool
+		err      bool
+	}{
+		{
+			name:     "all fields",
+			params:   all,
+			raw:      "1413,763,0.70,1.000",
+			expected: map[ConductivityMeasurement]float32{EC: 1413, TDS: 763, Salinity: 0.7, SpecificGravity: 1},
+			complete: true,
+		},
+		{
+			name:     "blank middle field",
+			params:   all,
+			raw:      "1413,,0.70,1.000",
+			expected: map[ConductivityMeasurement]float32{EC: 1413, Salinity: 0.7, SpecificGravity: 1},
+		},
+		{
+			name:     "more fields than params",
+			params:   []ConductivityMeasurement{EC, TDS},
+			raw:      "1413,763,0.70",
+			expected: map[ConductivityMeasurement]float32{EC: 1413, TDS: 763},
+		},
+		{
+			name:     "fewer fields than params",
+			params:   all,
+			raw:      "1413,763",
+			expected: map[ConductivityMeasurement]float32{EC: 1413, TDS: 763},
+		},
+		{
+			name:   "all fields unparseable",
+			params: []ConductivityMeasurement{EC, TDS},
+			raw:    "abc,*OK",
+			err:    true,
+		},
+	}
+
+	for _, c := range cases {
+		values, complete, e := parseValues(c.params, c.raw)
+
+		if c.err {
+			if e == nil {
+				t.Errorf("%s: expected an error, got %v", c.name, values)
+			}
+			continue
+		}
+
+		if e != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, e)
+			continue
+		}
+
+		if complete != c.complete {
+			t.Errorf("%s: expected complete %v, got %v", c.name, c.complete, complete)
+		}
+
+		if len(values) != len(c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, values)
+			continue
+		}
+
+		for k, v := range c.expected {
+			if got, ok := values[k]; !ok || !near(got, v) {
+				t.Errorf("%s: expected %v, got %v", c.name, c.expected, values)
+				break
+			}
+		}
+	}
+}
+
+//fakeTransport answers each command from responses, recording the commands sent
+type fakeTransport struct {
+	responses map[string]string
+	writes    []string
+	last      string
+}
+
+func (this *fakeTransport) Write(address uint8, data []byte) (int, error) {
+	this.last = string(data)
+	this.writes = append(this.writes, this.last)
+	return len(data), nil
+}
+
+func (this *fakeTransport) Read(address uint8, data []byte) (int, error) {
+	data[0] = byte(atlasScientific.ResponseSuccess)
+	copy(data[1:], this.responses[this.last])
+	return len(data), nil
+}
+
+type immediateDataReady struct{}
+
+func (this immediateDataReady) WaitDataReady(timeout time.Duration) error {
+	return nil
+}
+
+func newFakeConductivity(responses map[string]string) (*Conductivity, *fakeTransport) {
+	transport := &fakeTransport{responses: responses}
+
+	c, _ := New(100, nil, EC)
+	c.Transport = transport
+	c.DataReady = immediateDataReady{}
+
+	return c, transport
+}
+
+func TestReadCoalescesWithinWindow(t *testing.T) {
+	c, transport := newFakeConductivity(map[string]string{
+		"O,?": "?O,EC,TDS",
+		"R":   "1413,763",
+	})
+	c.CoalesceWindow = time.Minute
+
+	for i := 0; i < 3; i++ {
+		if reading, e := c.Read(); e != nil {
+			t.Fatalf("read %d: unexpected error: %v", i, e)
+		} else if reading.Value != 1413 {
+			t.Errorf("read %d: expected 1413, got %f", i, reading.Value)
+		}
+	}
+
+	if writes := strings.Join(transport.writes, " "); writes != "O,? R" {
+		t.Errorf("expected a single O,? and R, got %s", writes)
+	}
+
+	if e := c.OutputParameters(map[ConductivityMeasurement]bool{TDS: false}); e != nil {
+		t.Fatal(e)
+	}
+	transport.writes = nil
+
+	if _, e := c.Read(); e != nil {
+		t.Fatal(e)
+	}
+
+	if writes := strings.Join(transport.writes, " "); writes != "O,? R" {
+		t.Errorf("expected changing output parameters to end coalescing, got %s", writes)
+	}
+}
+
+func TestReadWithoutCoalescing(t *testing.T) {
+	c, transport := newFakeConductivity(map[string]string{
+		"O,?": "?O,EC",
+		"R":   "1413",
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, e := c.Read(); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	if writes := strings.Join(transport.writes, " "); writes != "O,? R O,? R" {
+		t.Errorf("expected every read to query the device, got %s", writes)
+	}
+}
+
+func TestToMicroSiemens(t *testing.T) {
+	cases := []struct {
+		name      string
+		value     float32
+		unit      CalibrationUnit
+		tdsFactor float32
+		expected  float32
+		err       bool
+	}{
+		{name: "microsiemens", value: 1413, unit: MicroSiemens, expected: 1413},
+		{name: "millisiemens", value: 12.88, unit: MilliSiemens, expected: 12880},
+		{name: "ppm on the NaCl scale", value: 500, unit: PPM, tdsFactor: NaClTDSFactor, expected: 1000},
+		{name: "ppm on the KCl scale", value: 700, unit: PPM, tdsFactor: KClTDSFactor, expected: 1000},
+		{name: "ppm without a factor", value: 500, unit: PPM, err: true},
+		{name: "unknown unit", value: 1, unit: CalibrationUnit(99), err: true},
+	}
+
+	for _, c := range cases {
+		v, e := ToMicroSiemens(c.value, c.unit, c.tdsFactor)
+
+		if c.err {
+			if e == nil {
+				t.Errorf("%s: expected an error, got %f", c.name, v)
+			}
+		} else if e != nil {
+			t.Errorf("%s: unexpected error: %v", c.name, e)
+		} else if !near(v, c.expected) {
+			t.Errorf("%s: expected %f, got %f", c.name, c.expected, v)
+		}
+	}
+}
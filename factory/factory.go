@@ -0,0 +1,49 @@
+package factory
+
+import (
+	"fmt"
+	"github.com/idahoakl/go-atlasScientific"
+	"github.com/idahoakl/go-atlasScientific/conductivity"
+	"github.com/idahoakl/go-atlasScientific/ph"
+	"github.com/idahoakl/go-i2c"
+)
+
+//UnsupportedDeviceError is returned when a device identifies as a type this
+//library has no driver for
+type UnsupportedDeviceError struct {
+	Type    string
+	Address uint8
+}
+
+func (this *UnsupportedDeviceError) Error() string {
+	return fmt.Sprintf("Unsupported device type '%s' at address %d", this.Type, this.Address)
+}
+
+//NewFromAddress issues "I" to the device at address and returns the matching
+//sensor.  Init is not called, since it may change device settings.
+func NewFromAddress(connection *i2c.I2C, address uint8) (atlasScientific.Sensor, error) {
+	if e := atlasScientific.ValidateAddress(address); e != nil {
+		return nil, e
+	}
+
+	device := atlasScientific.AtlasScientific{
+		Connection: connection,
+		Address:    address,
+	}
+
+	if info, e := device.GetDeviceInfo(); e != nil {
+		return nil, e
+	} else {
+		switch info.Type {
+		case "PH", "pH":
+			return ph.New(address, connection)
+		case "EC":
+			return conductivity.New(address, connection, conductivity.EC)
+		default:
+			return nil, &UnsupportedDeviceError{
+				Type:    info.Type,
+				Address: address,
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package atlasScientific
+
+import (
+	"sync"
+	"time"
+)
+
+//History is a fixed size ring buffer of the most recent readings, for short
+//term trends without a database.  It is safe for concurrent use.
+type History struct {
+	mtx      sync.Mutex
+	readings []Reading
+	next     int
+	full     bool
+}
+
+//NewHistory creates a History holding the last size readings
+func NewHistory(size int) *History {
+	if size < 1 {
+		size = 1
+	}
+
+	return &History{
+		readings: make([]Reading, size),
+	}
+}
+
+//Add records a reading, replacing the oldest once the buffer is full
+func (this *History) Add(reading Reading) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	this.readings[this.next] = reading
+	this.next = (this.next + 1) % len(this.readings)
+
+	if this.next == 0 {
+		this.full = true
+	}
+}
+
+//Last returns up to n of the most recent readings, oldest first
+func (this *History) Last(n int) []Reading {
+	all := this.all()
+
+	if n < len(all) {
+		all = all[len(all)-n:]
+	}
+
+	return all
+}
+
+//Since returns the readings taken after t, oldest first
+func (this *History) Since(t time.Time) []Reading {
+	all := this.all()
+
+	for i, r := range all {
+		if r.Time.After(t) {
+			return all[i:]
+		}
+	}
+
+	return nil
+}
+
+//Stats summarizes the valid readings in the buffer.  It returns nil when there
+//are none.
+func (this *History) Stats() *ReadingStats {
+	var values []float64
+
+	for _, r := range this.all() {
+		if r.Valid {
+			values = append(values, float64(r.Value))
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	return summarize(values)
+}
+
+//all returns a copy of the buffered readings, oldest first
+func (this *History) all() []Reading {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	if !this.full {
+		return append([]Reading(nil), this.readings[:this.next]...)
+	}
+
+	return append(append([]Reading(nil), this.readings[this.next:]...), this.readings[:this.next]...)
+}
@@ -0,0 +1,100 @@
+package atlasScientific
+
+import (
+	"testing"
+	"time"
+)
+
+func historyValues(readings []Reading) []float32 {
+	var values []float32
+	for _, r := range readings {
+		values = append(values, r.Value)
+	}
+	return values
+}
+
+func equalValues(a []float32, b []float32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestHistoryWraps(t *testing.T) {
+	h := NewHistory(3)
+	start := time.Now()
+
+	for i := 1; i <= 5; i++ {
+		h.Add(Reading{Value: float32(i), Time: start.Add(time.Duration(i) * time.Second), Valid: true})
+	}
+
+	cases := []struct {
+		n        int
+		expected []float32
+	}{
+		{n: 1, expected: []float32{5}},
+		{n: 2, expected: []float32{4, 5}},
+		{n: 3, expected: []float32{3, 4, 5}},
+		{n: 10, expected: []float32{3, 4, 5}},
+		{n: 0, expected: nil},
+	}
+
+	for _, c := range cases {
+		if v := historyValues(h.Last(c.n)); !equalValues(v, c.expected) {
+			t.Errorf("Last(%d): expected %v, got %v", c.n, c.expected, v)
+		}
+	}
+
+	if v := historyValues(h.Since(start.Add(3 * time.Second))); !equalValues(v, []float32{4, 5}) {
+		t.Errorf("Since: expected [4 5], got %v", v)
+	}
+
+	if v := h.Since(start.Add(time.Minute)); len(v) != 0 {
+		t.Errorf("Since future: expected nothing, got %v", v)
+	}
+}
+
+func TestHistoryPartial(t *testing.T) {
+	h := NewHistory(5)
+	h.Add(Reading{Value: 7, Valid: true})
+	h.Add(Reading{Value: 8, Valid: true})
+
+	if v := historyValues(h.Last(5)); !equalValues(v, []float32{7, 8}) {
+		t.Errorf("expected [7 8], got %v", v)
+	}
+}
+
+func TestHistoryStats(t *testing.T) {
+	h := NewHistory(4)
+
+	if h.Stats() != nil {
+		t.Error("expected nil stats for empty history")
+	}
+
+	h.Add(Reading{Value: 6, Valid: true})
+	h.Add(Reading{Value: 8, Valid: true})
+	h.Add(Reading{Value: 99, Valid: false})
+
+	stats := h.Stats()
+	if stats == nil {
+		t.Fatal("expected stats")
+	}
+
+	if stats.Count != 2 || stats.Mean != 7 || stats.Min != 6 || stats.Max != 8 {
+		t.Errorf("unexpected stats: %+v", *stats)
+	}
+}
+
+func TestRecordReadingAddsToHistory(t *testing.T) {
+	sensor := AtlasScientific{History: NewHistory(2)}
+	sensor.RecordReading(7, Bounds{Min: 0, Max: 14}, QualityGood)
+
+	if v := historyValues(sensor.History.Last(1)); !equalValues(v, []float32{7}) {
+		t.Errorf("expected [7], got %v", v)
+	}
+}
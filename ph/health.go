@@ -0,0 +1,146 @@
+package ph
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const (
+	//HealthySlope is the slope percentage of a new electrode
+	HealthySlope = 100
+	//ReplacementSlope is the slope percentage below which an electrode is
+	//considered worn out
+	ReplacementSlope = 85
+)
+
+type SlopeRecord struct {
+	Time  time.Time
+	Slope CalibrationSlope
+}
+
+//SlopeHistory tracks calibration slopes over time to estimate electrode aging
+type SlopeHistory struct {
+	mtx     sync.Mutex
+	records []SlopeRecord
+}
+
+//NewSlopeHistory creates a SlopeHistory seeded with previously saved records,
+//oldest first, so the trend survives process restarts
+func NewSlopeHistory(records []SlopeRecord) *SlopeHistory {
+	return &SlopeHistory{
+		records: append([]SlopeRecord(nil), records...),
+	}
+}
+
+//MarshalJSON encodes the recorded slopes for persistence
+func (this *SlopeHistory) MarshalJSON() ([]byte, error) {
+	return json.Marshal(this.Records())
+}
+
+//UnmarshalJSON replaces the recorded slopes with previously saved ones
+func (this *SlopeHistory) UnmarshalJSON(data []byte) error {
+	var records []SlopeRecord
+
+	if e := json.Unmarshal(data, &records); e != nil {
+		return e
+	}
+
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	this.records = records
+
+	return nil
+}
+
+//HealthScore returns 0-100 based on the weaker of the acid and base slopes,
+//where 100 is HealthySlope or better and 0 is ReplacementSlope or worse
+func (this *CalibrationSlope) HealthScore() float32 {
+	score := (this.weakest() - ReplacementSlope) / (HealthySlope - ReplacementSlope) * 100
+
+	if score < 0 {
+		return 0
+	} else if score > 100 {
+		return 100
+	}
+
+	return score
+}
+
+func (this *CalibrationSlope) weakest() float32 {
+	if this.AcidSlope < this.BaseSlope {
+		return this.AcidSlope
+	}
+
+	return this.BaseSlope
+}
+
+//Record adds slope to the history.  A slope identical to the latest record is
+//skipped, since repeated points would flatten the trend used by RemainingLife.
+func (this *SlopeHistory) Record(slope CalibrationSlope) {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	if n := len(this.records); n > 0 && this.records[n-1].Slope == slope {
+		return
+	}
+
+	this.records = append(this.records, SlopeRecord{
+		Time:  time.Now(),
+		Slope: slope,
+	})
+}
+
+//Records returns a copy of the recorded slopes, oldest first
+func (this *SlopeHistory) Records() []SlopeRecord {
+	this.mtx.Lock()
+	defer this.mtx.Unlock()
+
+	return append([]SlopeRecord(nil), this.records...)
+}
+
+//RemainingLife estimates how long until the weaker slope declines to
+//ReplacementSlope, using a least squares fit over the recorded slopes.  ok is
+//false when there are fewer than two records or the slope is not declining.
+func (this *SlopeHistory) RemainingLife() (remaining time.Duration, ok bool) {
+	records := this.Records()
+
+	if len(records) < 2 {
+		return 0, false
+	}
+
+	start := records[0].Time
+	var sumX, sumY, sumXY, sumXX float64
+
+	for _, r := range records {
+		x := r.Time.Sub(start).Hours()
+		y := float64(r.Slope.weakest())
+
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	n := float64(len(records))
+	denominator := n*sumXX - sumX*sumX
+
+	if denominator == 0 {
+		return 0, false
+	}
+
+	perHour := (n*sumXY - sumX*sumY) / denominator
+	if perHour >= 0 {
+		return 0, false
+	}
+
+	last := records[len(records)-1]
+	hours := (ReplacementSlope - float64(last.Slope.weakest())) / perHour
+
+	if hours < 0 {
+		return 0, true
+	}
+
+	return time.Duration(hours * float64(time.Hour)), true
+}
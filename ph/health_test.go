@@ -0,0 +1,111 @@
+package ph
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestHealthScore(t *testing.T) {
+	cases := []struct {
+		slope    CalibrationSlope
+		expected float32
+	}{
+		{slope: CalibrationSlope{AcidSlope: 100, BaseSlope: 100}, expected: 100},
+		{slope: CalibrationSlope{AcidSlope: 102, BaseSlope: 101}, expected: 100},
+		{slope: CalibrationSlope{AcidSlope: 92.5, BaseSlope: 99}, expected: 50},
+		{slope: CalibrationSlope{AcidSlope: 99, BaseSlope: 88}, expected: 20},
+		{slope: CalibrationSlope{AcidSlope: 85, BaseSlope: 95}, expected: 0},
+		{slope: CalibrationSlope{AcidSlope: 70, BaseSlope: 80}, expected: 0},
+	}
+
+	for _, c := range cases {
+		if score := c.slope.HealthScore(); score < c.expected-0.01 || score > c.expected+0.01 {
+			t.Errorf("%+v: expected %f, got %f", c.slope, c.expected, score)
+		}
+	}
+}
+
+func slopeRecord(start time.Time, days int, slope float32) SlopeRecord {
+	return SlopeRecord{
+		Time:  start.Add(time.Duration(days) * 24 * time.Hour),
+		Slope: CalibrationSlope{AcidSlope: slope, BaseSlope: slope},
+	}
+}
+
+func TestRemainingLife(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		records  []SlopeRecord
+		ok       bool
+		expected time.Duration
+	}{
+		{name: "no records", ok: false},
+		{name: "single record", records: []SlopeRecord{slopeRecord(start, 0, 99)}, ok: false},
+		{
+			name:    "stable",
+			records: []SlopeRecord{slopeRecord(start, 0, 99), slopeRecord(start, 30, 99)},
+			ok:      false,
+		},
+		{
+			name:    "improving",
+			records: []SlopeRecord{slopeRecord(start, 0, 95), slopeRecord(start, 30, 98)},
+			ok:      false,
+		},
+		{
+			name:     "declining one point per ten days",
+			records:  []SlopeRecord{slopeRecord(start, 0, 97), slopeRecord(start, 10, 96), slopeRecord(start, 20, 95)},
+			ok:       true,
+			expected: 100 * 24 * time.Hour,
+		},
+		{
+			name:     "already worn out",
+			records:  []SlopeRecord{slopeRecord(start, 0, 90), slopeRecord(start, 10, 80)},
+			ok:       true,
+			expected: 0,
+		},
+	}
+
+	for _, c := range cases {
+		remaining, ok := NewSlopeHistory(c.records).RemainingLife()
+
+		if ok != c.ok {
+			t.Errorf("%s: expected ok %v, got %v", c.name, c.ok, ok)
+		} else if diff := remaining - c.expected; diff < -time.Minute || diff > time.Minute {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, remaining)
+		}
+	}
+}
+
+func TestRecordSkipsUnchangedSlope(t *testing.T) {
+	history := NewSlopeHistory(nil)
+	history.Record(CalibrationSlope{AcidSlope: 99, BaseSlope: 98})
+	history.Record(CalibrationSlope{AcidSlope: 99, BaseSlope: 98})
+	history.Record(CalibrationSlope{AcidSlope: 97, BaseSlope: 98})
+
+	if n := len(history.Records()); n != 2 {
+		t.Errorf("expected 2 records, got %d", n)
+	}
+}
+
+func TestSlopeHistoryJSON(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := NewSlopeHistory([]SlopeRecord{slopeRecord(start, 0, 97), slopeRecord(start, 10, 96)})
+
+	data, e := json.Marshal(history)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	restored := &SlopeHistory{}
+	if e := json.Unmarshal(data, restored); e != nil {
+		t.Fatal(e)
+	}
+
+	records := restored.Records()
+	if len(records) != 2 || !records[1].Time.Equal(start.Add(240*time.Hour)) || records[1].Slope.AcidSlope != 96 {
+		t.Errorf("unexpected records after round trip: %+v", records)
+	}
+}
@@ -1,21 +1,48 @@
 package ph
 
 import (
+	"context"
 	"github.com/idahoakl/go-atlasScientific"
 	"github.com/idahoakl/go-i2c"
 	"strconv"
 	"regexp"
 	"time"
-	"errors"
-	"fmt"
 )
 
 var (
 	slopeRegex = regexp.MustCompile(`\?SLOPE,(?P<acidSlope>\d+\.?\d*),(?P<baseSlope>\d+\.?\d*)`)
+
+	description = atlasScientific.Description{
+		Type: "PH",
+		Unit: "pH",
+		ValidRange: atlasScientific.Bounds{
+			Min: 0,
+			Max: 14,
+		},
+	}
+
+	calibrationSpecs = []atlasScientific.CalibrationSpec{
+		{Point: "mid", RequiresValue: true, ValidRange: description.ValidRange, Delay: 1600 * time.Millisecond, ClearsExisting: true},
+		{Point: "low", RequiresValue: true, ValidRange: description.ValidRange, Delay: 1600 * time.Millisecond},
+		{Point: "high", RequiresValue: true, ValidRange: description.ValidRange, Delay: 1600 * time.Millisecond},
+	}
+
+	//Calibration frequently runs past the documented 1600ms, so allow a few short re-reads
+	calibrationRetryPolicy = atlasScientific.RetryPolicy{
+		Retries:    3,
+		RetryDelay: 300 * time.Millisecond,
+		MaxWait:    3000 * time.Millisecond,
+	}
 )
 
+var _ atlasScientific.Sensor = (*PH)(nil)
+var _ atlasScientific.Snapshotter = (*PH)(nil)
+var _ atlasScientific.LastReader = (*PH)(nil)
+
 type PH struct {
 	atlasScientific.AtlasScientific
+	//SlopeHistory records the slope after each successful calibration when set
+	SlopeHistory *SlopeHistory
 }
 
 type CalibrationSlope struct {
@@ -24,10 +51,17 @@ type CalibrationSlope struct {
 }
 
 func New(address uint8, connection *i2c.I2C) (*PH, error) {
+	if e := atlasScientific.ValidateAddress(address); e != nil {
+		return nil, e
+	}
+
+	calRetry := calibrationRetryPolicy
+
 	ph := &PH{
-		atlasScientific.AtlasScientific {
+		AtlasScientific: atlasScientific.AtlasScientific {
 			Connection: connection,
 			Address: address,
+			CalibrationRetryPolicy: &calRetry,
 		},
 	}
 
@@ -35,18 +69,114 @@ func New(address uint8, connection *i2c.I2C) (*PH, error) {
 	return ph, nil
 }
 
+//NewLazy creates a PH without opening the bus.  Connect must be called before
+//any commands are issued.
+func NewLazy(address uint8, bus int) (*PH, error) {
+	if ph, e := New(address, nil); e != nil {
+		return nil, e
+	} else {
+		ph.Bus = bus
+		return ph, nil
+	}
+}
+
 func (this *PH) GetValue() (float32, error) {
-	if rawValue, e := this.GetRawValue(); e != nil {
+	if reading, e := this.Read(); e != nil {
 		return atlasScientific.ERROR_VALUE, e
 	} else {
-		if ph, e := strconv.ParseFloat(rawValue, 32); e != nil {
-			return 0, e
-		} else {
-			return float32(ph), nil
-		}
+		return reading.Value, nil
+	}
+}
+
+func (this *PH) Read() (atlasScientific.Reading, error) {
+	if rawValue, e := this.GetRawValue(); e != nil {
+		return atlasScientific.Reading{}, e
+	} else {
+		return this.parseReading(rawValue)
+	}
+}
+
+func (this *PH) parseReading(rawValue string) (atlasScientific.Reading, error) {
+	if ph, e := strconv.ParseFloat(rawValue, 32); e != nil {
+		return atlasScientific.Reading{}, e
+	} else {
+		return this.RecordReading(float32(ph), description.ValidRange, atlasScientific.QualityGood), nil
+	}
+}
+
+//ReadDebug takes a reading and returns the raw response alongside the parsed
+//value.  The DebugReading is non-nil whenever data was read, even on error.
+func (this *PH) ReadDebug() (*atlasScientific.DebugReading, error) {
+	if debug, e := this.GetRawValueDebug(); e != nil {
+		return debug, e
+	} else if reading, e := this.parseReading(debug.Payload); e != nil {
+		return debug, e
+	} else {
+		debug.Value = reading.Value
+		return debug, nil
+	}
+}
+
+//Verify checks that the device at Address identifies as a pH circuit
+func (this *PH) Verify() error {
+	return this.VerifyDeviceType(description.Type)
+}
+
+//ReadCompensated takes a reading compensated to the provider's temperature,
+//using the combined RT command where the firmware supports it
+func (this *PH) ReadCompensated(provider atlasScientific.CompensationProvider) (atlasScientific.Reading, error) {
+	if rawValue, e := this.ReadRawCompensated(provider); e != nil {
+		return atlasScientific.Reading{}, e
+	} else {
+		return this.parseReading(rawValue)
+	}
+}
+
+func (this *PH) Describe() atlasScientific.Description {
+	return description
+}
+
+//Calibrate performs calibration for one of CalibrationSpecs.  Valid calPoint
+//values: mid, low, high
+func (this *PH) Calibrate(calPoint string, value float32) error {
+	if spec, e := atlasScientific.FindCalibrationSpec(calibrationSpecs, calPoint); e != nil {
+		return e
+	} else if e := this.AtlasScientific.Calibrate(spec, value); e != nil {
+		return e
+	} else {
+		this.recordSlope()
+		return nil
+	}
+}
+
+//recordSlope adds the current slope to SlopeHistory.  The calibration itself
+//succeeded, so a failed slope query is only logged.
+func (this *PH) recordSlope() {
+	if this.SlopeHistory == nil {
+		return
+	}
+
+	if slope, e := this.GetCalibrationSlope(); e != nil {
+		this.GetContextLogger().WithField("error", e).Warn("Unable to read slope after calibration")
+	} else {
+		this.SlopeHistory.Record(*slope)
 	}
 }
 
+func (this *PH) CalibrationSpecs() []atlasScientific.CalibrationSpec {
+	return calibrationSpecs
+}
+
+//ReadAverage takes n readings interval apart and summarizes them
+func (this *PH) ReadAverage(ctx context.Context, n int, interval time.Duration) (*atlasScientific.ReadingStats, error) {
+	return atlasScientific.ReadAverage(ctx, this, n, interval)
+}
+
+//SelfTest compares a stabilized reading against a buffer of known pH
+func (this *PH) SelfTest(expected float32, tolerance float32) (*atlasScientific.SelfTestResult, error) {
+	return atlasScientific.SelfTest(this, expected, tolerance)
+}
+
 //Example instruction sequence:
 //	Write: SLOPE,?
 //	Wait: 300ms
@@ -77,24 +207,9 @@ func (this *PH) GetCalibrationSlope() (*CalibrationSlope, error) {
 }
 
 //Example instruction sequence:
-//	Write: CAL,mid,7.00
+//	Write: CAL,mid,7
 //	Wait: 1600ms
 //	Read: <successful read, no data>
 func (this *PH) Calibration(calPoint string, phValue float32) error {
-	this.Mtx.Lock()
-	defer this.Mtx.Unlock()
-
-	if calPoint != "high" && calPoint != "mid" && calPoint != "low" {
-		return errors.New("Invalid calPoint value.  Valid values: high, mid low")
-	}
-
-	if _, e := this.Write(fmt.Sprintf("CAL,%s,%f", calPoint, phValue)); e != nil {
-		return e
-	}
-
-	if _, e := this.PerformRead(1600 * time.Millisecond); e != nil {
-		return e;
-	}
-
-	return nil
-}
\ No newline at end of file
+	return this.Calibrate(calPoint, phValue)
+}
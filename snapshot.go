@@ -0,0 +1,114 @@
+package atlasScientific
+
+import (
+	"fmt"
+	"reflect"
+)
+
+//Snapshot captures a device's configuration so it can be stored as JSON and
+//compared against another device or an earlier capture.  Sensor specific
+//settings are left empty by sensors that do not support them.
+type Snapshot struct {
+	Address          uint8
+	Name             string
+	Type             string
+	FirmwareVersion  float32
+	TempCompensation float32
+	CalibrationCount int
+	LedOn            bool
+	ProtocolLock     bool
+	ProbeType        float32  `json:",omitempty"`
+	OutputParameters []string `json:",omitempty"`
+}
+
+//Snapshotter is implemented by sensors that can capture their configuration
+type Snapshotter interface {
+	Snapshot() (*Snapshot, error)
+}
+
+//SnapshotDifference is a single field that differs between two snapshots
+type SnapshotDifference struct {
+	Field string
+	Old   string
+	New   string
+}
+
+func (this SnapshotDifference) String() string {
+	return fmt.Sprintf("%s: %s -> %s", this.Field, this.Old, this.New)
+}
+
+//Snapshot queries the device for the configuration shared by all sensors
+func (this *AtlasScientific) Snapshot() (*Snapshot, error) {
+	snapshot := Snapshot{Address: this.Address}
+
+	if name, e := this.GetName(); e != nil {
+		return nil, e
+	} else {
+		snapshot.Name = name
+	}
+
+	if info, e := this.GetDeviceInfo(); e != nil {
+		return nil, e
+	} else {
+		snapshot.Type = info.Type
+		snapshot.FirmwareVersion = info.FirmwareVersion
+	}
+
+	if tempC, e := this.GetTempCompensation(); e != nil {
+		return nil, e
+	} else {
+		snapshot.TempCompensation = tempC
+	}
+
+	if count, e := this.GetCalibrationCount(); e != nil {
+		return nil, e
+	} else {
+		snapshot.CalibrationCount = count
+	}
+
+	if ledOn, e := this.GetLedStatus(); e != nil {
+		return nil, e
+	} else {
+		snapshot.LedOn = ledOn
+	}
+
+	if locked, e := this.GetProtocolLock(); e != nil {
+		return nil, e
+	} else {
+		snapshot.ProtocolLock = locked
+	}
+
+	return &snapshot, nil
+}
+
+//Diff returns the fields that changed from this snapshot to other, in field
+//order.  A nil snapshot on either side is treated as empty.
+func (this *Snapshot) Diff(other *Snapshot) []SnapshotDifference {
+	var diffs []SnapshotDifference
+
+	if this == nil {
+		this = &Snapshot{}
+	}
+
+	if other == nil {
+		other = &Snapshot{}
+	}
+
+	oldVal := reflect.ValueOf(*this)
+	newVal := reflect.ValueOf(*other)
+
+	for i := 0; i < oldVal.NumField(); i++ {
+		o := fmt.Sprint(oldVal.Field(i).Interface())
+		n := fmt.Sprint(newVal.Field(i).Interface())
+
+		if o != n {
+			diffs = append(diffs, SnapshotDifference{
+				Field: oldVal.Type().Field(i).Name,
+				Old:   o,
+				New:   n,
+			})
+		}
+	}
+
+	return diffs
+}
@@ -0,0 +1,84 @@
+package atlasScientific
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSnapshotDiff(t *testing.T) {
+	base := Snapshot{
+		Address:          99,
+		Name:             "tank1",
+		Type:             "EC",
+		FirmwareVersion:  2.1,
+		TempCompensation: 25,
+		CalibrationCount: 2,
+		LedOn:            true,
+		ProbeType:        1,
+		OutputParameters: []string{"EC", "TDS"},
+	}
+
+	changed := func(f func(*Snapshot)) *Snapshot {
+		s := base
+		s.OutputParameters = append([]string(nil), base.OutputParameters...)
+		f(&s)
+		return &s
+	}
+
+	cases := []struct {
+		name     string
+		old      *Snapshot
+		new      *Snapshot
+		expected []SnapshotDifference
+	}{
+		{
+			name: "identical",
+			old:  &base,
+			new:  changed(func(s *Snapshot) {}),
+		},
+		{
+			name: "single field",
+			old:  &base,
+			new:  changed(func(s *Snapshot) { s.CalibrationCount = 3 }),
+			expected: []SnapshotDifference{
+				{Field: "CalibrationCount", Old: "2", New: "3"},
+			},
+		},
+		{
+			name: "multiple fields in field order",
+			old:  &base,
+			new: changed(func(s *Snapshot) {
+				s.ProtocolLock = true
+				s.Name = "tank2"
+				s.OutputParameters = []string{"EC"}
+			}),
+			expected: []SnapshotDifference{
+				{Field: "Name", Old: "tank1", New: "tank2"},
+				{Field: "ProtocolLock", Old: "false", New: "true"},
+				{Field: "OutputParameters", Old: "[EC TDS]", New: "[EC]"},
+			},
+		},
+		{
+			name: "nil other",
+			old:  &Snapshot{Address: 99},
+			new:  nil,
+			expected: []SnapshotDifference{
+				{Field: "Address", Old: "99", New: "0"},
+			},
+		},
+		{
+			name: "nil receiver",
+			old:  nil,
+			new:  &Snapshot{LedOn: true},
+			expected: []SnapshotDifference{
+				{Field: "LedOn", Old: "false", New: "true"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		if diffs := c.old.Diff(c.new); !reflect.DeepEqual(diffs, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, diffs)
+		}
+	}
+}
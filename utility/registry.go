@@ -0,0 +1,106 @@
+package utility
+
+import (
+	"bufio"
+	"fmt"
+	log "github.com/Sirupsen/logrus"
+	"github.com/idahoakl/go-atlasScientific"
+	"strings"
+)
+
+type CmdFunc func(*bufio.Reader)
+
+type Cmd struct {
+	Name string
+	Desc string
+	Exec CmdFunc
+}
+
+//Registry holds the commands offered by a utility binary.  Base commands shared
+//by every sensor are registered by NewRegistry; sensor specific commands are
+//appended with Register.
+type Registry struct {
+	cmds   []Cmd
+	byName map[string]Cmd
+}
+
+func NewRegistry(probe atlasScientific.AtlasScientificSensor) *Registry {
+	r := &Registry{
+		byName: make(map[string]Cmd),
+	}
+
+	bind := func(f func(*bufio.Reader, atlasScientific.AtlasScientificSensor)) CmdFunc {
+		return func(reader *bufio.Reader) {
+			f(reader, probe)
+		}
+	}
+
+	r.Register("info", DeviceInfoDesc, bind(InfoCmd))
+	r.Register("stat", DeviceStatDesc, bind(StatusCmd))
+	r.Register("read", ReadingDesc, bind(ReadCmd))
+	r.Register("poll", PollDesc, bind(PollCmd))
+	r.Register("spark", SparkDesc, bind(SparkCmd))
+	r.Register("temp", TempCompDesc, bind(TempCompCmd))
+	r.Register("snap", SnapshotDesc, bind(SnapshotCmd))
+
+	return r
+}
+
+//Register adds a command, replacing any existing command with the same name
+func (this *Registry) Register(name string, desc string, exec CmdFunc) {
+	cmd := Cmd{Name: name, Desc: desc, Exec: exec}
+	key := strings.ToLower(name)
+
+	if _, ok := this.byName[key]; ok {
+		for i := range this.cmds {
+			if strings.ToLower(this.cmds[i].Name) == key {
+				this.cmds[i] = cmd
+			}
+		}
+	} else {
+		this.cmds = append(this.cmds, cmd)
+	}
+
+	this.byName[key] = cmd
+}
+
+//Run prompts for and executes commands until reading input fails
+func (this *Registry) Run(reader *bufio.Reader) {
+	for {
+		this.PrintActions()
+		fmt.Print("-> ")
+		if e := this.dispatch(reader); e != nil {
+			log.Fatal(e)
+		}
+	}
+}
+
+//Lookup finds a command by name, ignoring case
+func (this *Registry) Lookup(name string) (Cmd, bool) {
+	cmd, ok := this.byName[strings.ToLower(name)]
+	return cmd, ok
+}
+
+//dispatch reads a single command and executes it
+func (this *Registry) dispatch(reader *bufio.Reader) error {
+	if text, e := ReadCommand(reader); e != nil {
+		return e
+	} else {
+		if cmd, ok := this.Lookup(text); ok {
+			cmd.Exec(reader)
+		} else {
+			fmt.Printf("Unknown command: '%s'\n", text)
+		}
+	}
+
+	return nil
+}
+
+func (this *Registry) PrintActions() {
+	println("Please select a command:")
+	println("Command\t\tNote")
+
+	for _, cmd := range this.cmds {
+		fmt.Printf("%s\t\t%s\n", cmd.Name, cmd.Desc)
+	}
+}
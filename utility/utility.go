@@ -2,6 +2,7 @@ package utility
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	log "github.com/Sirupsen/logrus"
 	"github.com/idahoakl/go-atlasScientific"
@@ -16,14 +17,83 @@ const (
 	ReadingDesc    = "Take reading"
 	TempCompDesc   = "Get/set temperature compensation"
 	PollDesc	   = "Continuously get reading every second"
+	SparkDesc      = "Continuously get reading every second with a trend sparkline"
+	SnapshotDesc   = "Capture device configuration and diff against the previous capture"
 )
 
+const sparkWidth = 30
+
+//ValueFormat controls how readings of one sensor type are displayed
+type ValueFormat struct {
+	Precision int
+	//Scale multiplies values before display, e.g. 0.001 to show uS/cm as mS/cm
+	Scale float32
+	Unit  string
+}
+
+//Formats holds the display format per sensor type, keyed by Description.Type.
+//Sensors without an entry are displayed with six decimals and no unit.
+var Formats = map[string]ValueFormat{
+	"PH":  {Precision: 2, Unit: "pH"},
+	"EC":  {Precision: 0, Unit: "uS/cm"},
+	"TDS": {Precision: 0, Unit: "ppm"},
+	"S":   {Precision: 2, Unit: "PSU"},
+	"SG":  {Precision: 3},
+}
+
+func (this ValueFormat) Format(v float32) string {
+	if this.Scale != 0 {
+		v *= this.Scale
+	}
+
+	str := strconv.FormatFloat(float64(v), 'f', this.Precision, 32)
+
+	if this.Unit != "" {
+		str += " " + this.Unit
+	}
+
+	return str
+}
+
+//FormatValue formats v using the entry in Formats for the probe's type
+func FormatValue(probe atlasScientific.AtlasScientificSensor, v float32) string {
+	if sensor, ok := probe.(atlasScientific.Sensor); ok {
+		if format, ok := Formats[sensor.Describe().Type]; ok {
+			return format.Format(v)
+		}
+	}
+
+	return fmt.Sprintf("%f", v)
+}
+
+var (
+	//SoakDuration is how long readings must stay stable before a calibration
+	//value is accepted.  Zero disables soaking.
+	SoakDuration = 30 * time.Second
+	//SoakTolerance is the allowed drift, as a fraction of the reading, for
+	//readings to count as stable
+	SoakTolerance float32 = 0.005
+)
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+//ReadAndSanitizeLine reads a line and trims the line ending (LF or CRLF) along
+//with any surrounding whitespace
 func ReadAndSanitizeLine(reader *bufio.Reader) (string, error) {
 	if text, e := reader.ReadString('\n'); e != nil {
 		return "", e
 	} else {
-		text = strings.TrimRight(text, "\n")
-		return text, nil
+		return strings.TrimSpace(text), nil
+	}
+}
+
+//ReadCommand reads a sanitized line and lower-cases it for matching against
+//command names and options
+func ReadCommand(reader *bufio.Reader) (string, error) {
+	if text, e := ReadAndSanitizeLine(reader); e != nil {
+		return "", e
+	} else {
+		return strings.ToLower(text), nil
 	}
 }
 
@@ -37,6 +107,32 @@ func InfoCmd(reader *bufio.Reader, probe atlasScientific.AtlasScientificSensor)
 	}
 }
 
+//lastSnapshot is the previous capture taken by SnapshotCmd
+var lastSnapshot *atlasScientific.Snapshot
+
+func SnapshotCmd(reader *bufio.Reader, probe atlasScientific.AtlasScientificSensor) {
+	println("\nDevice Snapshot")
+	if snapshotter, ok := probe.(atlasScientific.Snapshotter); !ok {
+		println("\tSnapshots not supported by this sensor")
+	} else if s, e := snapshotter.Snapshot(); e != nil {
+		log.Fatal(e)
+	} else if b, e := json.MarshalIndent(s, "\t", "  "); e != nil {
+		log.Fatal(e)
+	} else {
+		fmt.Printf("\t%s\n", b)
+
+		if lastSnapshot != nil {
+			diffs := lastSnapshot.Diff(s)
+			fmt.Printf("\tChanges since previous capture: %d\n", len(diffs))
+			for _, d := range diffs {
+				fmt.Printf("\t\t%s\n", d)
+			}
+		}
+
+		lastSnapshot = s
+	}
+}
+
 func StatusCmd(reader *bufio.Reader, probe atlasScientific.AtlasScientificSensor) {
 	println("\nDevice Status")
 	if s, e := probe.GetStatus(); e != nil {
@@ -56,7 +152,7 @@ func TempCompCmd(reader *bufio.Reader, probe atlasScientific.AtlasScientificSens
 	println("\nTemperature compensation")
 	println("\tget or <value>?  [get] ->")
 
-	if text, e := ReadAndSanitizeLine(reader); e != nil {
+	if text, e := ReadCommand(reader); e != nil {
 		log.Fatal(e)
 	} else {
 		if text == "" || text == "get" {
@@ -93,10 +189,179 @@ func PollCmd(reader *bufio.Reader, probe atlasScientific.AtlasScientificSensor)
 	}
 }
 
+func SparkCmd(reader *bufio.Reader, probe atlasScientific.AtlasScientificSensor) {
+	println("\nReading every second")
+	var values []float32
+
+	for {
+		if v, e := probe.GetValue(); e != nil {
+			log.Fatal(e)
+		} else {
+			values = append(values, v)
+			if len(values) > sparkWidth {
+				values = values[1:]
+			}
+
+			fmt.Printf("\r\t%s %s", Sparkline(values), FormatValue(probe, v))
+		}
+		time.Sleep(1 * time.Second)
+	}
+}
+
+//Sparkline renders values as a row of unicode block characters scaled between
+//the minimum and maximum value
+func Sparkline(values []float32) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	min, max := values[0], values[0]
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+
+	spark := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if max > min {
+			level = int((v - min) / (max - min) * float32(len(sparkBlocks)-1))
+		}
+		spark[i] = sparkBlocks[level]
+	}
+
+	return string(spark)
+}
+
+//CalibrationCmd gets the calibration count, clears calibration, or calibrates
+//one of the points described by the probe's CalibrationSpecs
+func CalibrationCmd(reader *bufio.Reader, probe atlasScientific.Sensor) {
+	specs := probe.CalibrationSpecs()
+	var points []string
+
+	for _, spec := range specs {
+		points = append(points, spec.Point)
+	}
+
+	fmt.Printf("\n%s calibration\n", probe.Describe().Type)
+	fmt.Printf("\tget, %s, clear? [get] ->\n", strings.Join(points, ", "))
+
+	for {
+		if text, e := ReadCommand(reader); e != nil {
+			log.Fatal(e)
+		} else if text == "" || text == "get" {
+			if i, e := probe.GetCalibrationCount(); e != nil {
+				log.Fatal(e)
+			} else {
+				fmt.Printf("\tCalibration point count: %d\n", i)
+			}
+			return
+		} else if text == "clear" {
+			if CalClearConfirm(reader) {
+				if e := probe.ClearCalibration(); e != nil {
+					log.Fatal(e)
+				} else {
+					println("\tCalibration cleared")
+				}
+			}
+			return
+		} else if spec, e := atlasScientific.FindCalibrationSpec(specs, text); e != nil {
+			fmt.Printf("\t'%s' not recognized as a command.  Please try again\n", text)
+		} else {
+			performCalibration(reader, probe, spec)
+			return
+		}
+	}
+}
+
+func performCalibration(reader *bufio.Reader, probe atlasScientific.Sensor, spec atlasScientific.CalibrationSpec) {
+	if spec.ClearsExisting && !CalClearConfirm(reader) {
+		return
+	}
+
+	var val float32
+	unit := probe.Describe().Unit
+
+	if spec.RequiresValue && SoakDuration > 0 {
+		soak(probe)
+	}
+
+	if spec.RequiresValue {
+		val = readFloat(reader, fmt.Sprintf("\tEnter %s value for '%s' ->", unit, spec.Point))
+	}
+
+	if e := probe.Calibrate(spec.Point, val); e != nil {
+		log.Fatal(e)
+	} else if spec.RequiresValue {
+		fmt.Printf("\tcalibration point '%s' set to: %f %s\n", spec.Point, val, unit)
+	} else {
+		fmt.Printf("\tcalibration point '%s' set\n", spec.Point)
+	}
+}
+
+//soak takes a reading every second until readings have stayed within
+//SoakTolerance of each other for SoakDuration, since probes need time to
+//equilibrate in calibration solution
+func soak(probe atlasScientific.Sensor) {
+	fmt.Printf("\tSoaking for %s until readings are stable\n", SoakDuration)
+
+	var baseline float32
+	var stableSince time.Time
+
+	for {
+		v, e := probe.GetValue()
+		if e != nil {
+			log.Fatal(e)
+		}
+
+		now := time.Now()
+		if stableSince.IsZero() || abs(v-baseline) > abs(baseline)*SoakTolerance {
+			baseline = v
+			stableSince = now
+		}
+
+		remaining := SoakDuration - now.Sub(stableSince)
+		if remaining <= 0 {
+			fmt.Printf("\r\tStable at %s          \n", FormatValue(probe, v))
+			return
+		}
+
+		fmt.Printf("\r\t%3ds remaining  %s", int(remaining.Seconds()+0.5), FormatValue(probe, v))
+		time.Sleep(1 * time.Second)
+	}
+}
+
+func abs(f float32) float32 {
+	if f < 0 {
+		return -f
+	}
+
+	return f
+}
+
+//readFloat prompts until a line parses as a float32
+func readFloat(reader *bufio.Reader, prompt string) float32 {
+	for {
+		print(prompt)
+
+		if text, e := ReadAndSanitizeLine(reader); e != nil {
+			log.Fatal(e)
+		} else if f, e := strconv.ParseFloat(text, 32); e != nil {
+			fmt.Printf("\tUnable to parse value '%s' as float32.  Please try again.  Error:  %s\n", text, e)
+		} else {
+			return float32(f)
+		}
+	}
+}
+
 func CalClearConfirm(reader *bufio.Reader) bool {
 	println("\tThis command will clear all existing calibration.  Continue? yes/no [no] ->")
 
-	if text, e := ReadAndSanitizeLine(reader); e != nil {
+	if text, e := ReadCommand(reader); e != nil {
 		log.Fatal(e)
 	} else {
 		return text == "yes"
@@ -109,6 +374,6 @@ func readAndPrintProbe(probe atlasScientific.AtlasScientificSensor) {
 	if v, e := probe.GetValue(); e != nil {
 		log.Fatal(e)
 	} else {
-		fmt.Printf("\t%f\n", v)
+		fmt.Printf("\t%s\n", FormatValue(probe, v))
 	}
 }
@@ -0,0 +1,105 @@
+package utility
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadAndSanitizeLine(t *testing.T) {
+	cases := map[string]string{
+		"read\r\n":   "read",
+		"  Get \n":   "Get",
+		"\t7.00\r\n": "7.00",
+		"\n":         "",
+		"cal mid \n": "cal mid",
+	}
+
+	for input, expected := range cases {
+		reader := bufio.NewReader(strings.NewReader(input))
+
+		if text, e := ReadAndSanitizeLine(reader); e != nil {
+			t.Errorf("%q: unexpected error: %v", input, e)
+		} else if text != expected {
+			t.Errorf("%q: expected %q, got %q", input, expected, text)
+		}
+	}
+}
+
+func TestReadAndSanitizeLineEOF(t *testing.T) {
+	reader := bufio.NewReader(strings.NewReader("read"))
+
+	if _, e := ReadAndSanitizeLine(reader); e != io.EOF {
+		t.Errorf("expected io.EOF, got %v", e)
+	}
+}
+
+func TestReadCommand(t *testing.T) {
+	cases := map[string]string{
+		"read\r\n":  "read",
+		"  Get \n":  "get",
+		"PHCAL\r\n": "phcal",
+	}
+
+	for input, expected := range cases {
+		reader := bufio.NewReader(strings.NewReader(input))
+
+		if text, e := ReadCommand(reader); e != nil {
+			t.Errorf("%q: unexpected error: %v", input, e)
+		} else if text != expected {
+			t.Errorf("%q: expected %q, got %q", input, expected, text)
+		}
+	}
+}
+
+func TestRegistryLookupIgnoresCase(t *testing.T) {
+	r := &Registry{byName: make(map[string]Cmd)}
+	r.Register("phCal", "Calibrate", func(*bufio.Reader) {})
+
+	for _, name := range []string{"phCal", "phcal", "PHCAL"} {
+		if cmd, ok := r.Lookup(name); !ok {
+			t.Errorf("%q: command not found", name)
+		} else if cmd.Name != "phCal" {
+			t.Errorf("%q: expected phCal, got %q", name, cmd.Name)
+		}
+	}
+}
+
+func TestRegistryRegisterReplaces(t *testing.T) {
+	r := &Registry{byName: make(map[string]Cmd)}
+	r.Register("read", "First", func(*bufio.Reader) {})
+	r.Register("READ", "Second", func(*bufio.Reader) {})
+
+	if len(r.cmds) != 1 {
+		t.Fatalf("expected 1 command, got %d", len(r.cmds))
+	}
+
+	if cmd, _ := r.Lookup("read"); cmd.Desc != "Second" {
+		t.Errorf("expected replacement command, got %q", cmd.Desc)
+	}
+}
+
+func TestRegistryDispatch(t *testing.T) {
+	var ran []string
+
+	r := &Registry{byName: make(map[string]Cmd)}
+	r.Register("phCal", "Calibrate", func(*bufio.Reader) { ran = append(ran, "phCal") })
+	r.Register("read", "Read", func(*bufio.Reader) { ran = append(ran, "read") })
+
+	reader := bufio.NewReader(strings.NewReader("  PhCal \r\nREAD\nbogus\n"))
+
+	for i := 0; i < 3; i++ {
+		if e := r.dispatch(reader); e != nil {
+			t.Fatalf("unexpected error: %v", e)
+		}
+	}
+
+	if strings.Join(ran, ",") != "phCal,read" {
+		t.Errorf("expected phCal,read to run, got %v", ran)
+	}
+
+	if e := r.dispatch(reader); e != io.EOF {
+		t.Errorf("expected io.EOF at end of input, got %v", e)
+	}
+}